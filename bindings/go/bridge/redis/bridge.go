@@ -0,0 +1,202 @@
+package redis
+
+import (
+	"time"
+
+	jasonisnthappy "github.com/sohzm/jasonisnthappy/bindings/go"
+)
+
+// streamKey is the Redis Stream each collection's events are republished to.
+func streamKey(collection string) string {
+	return "jsnh:changes:" + collection
+}
+
+// Event is a change event delivered by SubscribeRedis.
+type Event struct {
+	Collection string
+	Operation  string
+	DocID      string
+	DocJSON    string
+	// StreamID is the Redis Stream entry ID this event was read from; save
+	// it and pass it back as SubscribeRedis's offset to start a new consumer
+	// group from here. A group that already exists keeps its own position.
+	StreamID string
+}
+
+// Publisher republishes a collection's watch events onto a Redis Stream.
+type Publisher struct {
+	conn   *conn
+	handle *jasonisnthappy.WatchHandle
+}
+
+// PublishWatch starts watching coll and republishes every event to a Redis
+// Stream keyed by the collection name (XADD jsnh:changes:<collection> * ...).
+// Callers on other processes can pick the events up with SubscribeRedis.
+func PublishWatch(coll *jasonisnthappy.Collection, collectionName, redisURL string) (*Publisher, error) {
+	c, err := dial(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	key := streamKey(collectionName)
+
+	handle, err := coll.WatchStart("", func(collection, operation, docID, docJSON string) {
+		_, _ = c.do("XADD", key, "*",
+			"operation", operation,
+			"doc_id", docID,
+			"doc_json", docJSON,
+		)
+	})
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return &Publisher{conn: c, handle: handle}, nil
+}
+
+// Close stops watching and disconnects from Redis.
+func (p *Publisher) Close() {
+	if p.handle != nil {
+		p.handle.Stop()
+	}
+	if p.conn != nil {
+		p.conn.Close()
+	}
+}
+
+// Subscription is a live XREAD/XREADGROUP consumer started by SubscribeRedis.
+type Subscription struct {
+	conn     *conn
+	redisURL string
+	stop     chan struct{}
+	done     chan struct{}
+	lastID   string
+}
+
+// SubscribeRedis consumes a collection's change stream from Redis on a
+// remote process, decoding events and invoking cb for each one. group and
+// consumer identify this reader within a Redis consumer group so a late
+// subscriber resumes from offset (a Redis Stream ID, e.g. "$" for
+// new-events-only or "0" to replay everything) instead of re-reading events
+// another consumer already acknowledged.
+func SubscribeRedis(redisURL, collection, group, consumer, offset string, cb func(Event)) (*Subscription, error) {
+	c, err := dial(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	key := streamKey(collection)
+
+	// Idempotently create the consumer group; ignore the "already exists" error.
+	_, _ = c.do("XGROUP", "CREATE", key, group, offset, "MKSTREAM")
+
+	sub := &Subscription{conn: c, redisURL: redisURL, stop: make(chan struct{}), done: make(chan struct{})}
+
+	go sub.loop(key, group, consumer, cb)
+
+	return sub, nil
+}
+
+// minReconnectBackoff and maxReconnectBackoff bound the delay between
+// redial attempts after the connection drops, so a Redis restart is
+// picked back up quickly but a sustained outage doesn't spin.
+const (
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+func (s *Subscription) loop(key, group, consumer string, cb func(Event)) {
+	defer close(s.done)
+
+	backoff := minReconnectBackoff
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		r, err := s.conn.do("XREADGROUP", "GROUP", group, consumer, "BLOCK", "1000", "COUNT", "100", "STREAMS", key, ">")
+		if err != nil {
+			if !s.reconnect(key, group) {
+				select {
+				case <-s.stop:
+					return
+				case <-time.After(backoff):
+				}
+				if backoff < maxReconnectBackoff {
+					backoff *= 2
+				}
+				continue
+			}
+			backoff = minReconnectBackoff
+			continue
+		}
+		if r.isNil || len(r.items) == 0 {
+			continue // BLOCK timeout with nothing new
+		}
+
+		for _, streamReply := range r.items {
+			if len(streamReply.items) != 2 {
+				continue
+			}
+			entries := streamReply.items[1]
+			for _, entry := range entries.items {
+				s.handleEntry(key, group, entry, cb)
+			}
+		}
+	}
+}
+
+// reconnect replaces s.conn with a fresh connection and idempotently
+// re-creates the consumer group, so a dropped TCP connection doesn't
+// silently stop event delivery until the process is restarted. It
+// reports whether the redial succeeded.
+func (s *Subscription) reconnect(key, group string) bool {
+	c, err := dial(s.redisURL)
+	if err != nil {
+		return false
+	}
+
+	// Idempotently re-create the consumer group; ignore the "already
+	// exists" error. offset doesn't matter here since the group already
+	// has a cursor position on the Redis side if it previously existed.
+	_, _ = c.do("XGROUP", "CREATE", key, group, "$", "MKSTREAM")
+
+	s.conn.Close()
+	s.conn = c
+	return true
+}
+
+func (s *Subscription) handleEntry(key, group string, entry reply, cb func(Event)) {
+	if len(entry.items) != 2 {
+		return
+	}
+	id := entry.items[0].str
+	fields := entry.items[1].items
+
+	ev := Event{StreamID: id}
+	for i := 0; i+1 < len(fields); i += 2 {
+		switch fields[i].str {
+		case "operation":
+			ev.Operation = fields[i+1].str
+		case "doc_id":
+			ev.DocID = fields[i+1].str
+		case "doc_json":
+			ev.DocJSON = fields[i+1].str
+		}
+	}
+
+	cb(ev)
+	s.lastID = id
+	_, _ = s.conn.do("XACK", key, group, id)
+}
+
+// Close stops consuming and disconnects from Redis.
+func (s *Subscription) Close() {
+	close(s.stop)
+	<-s.done
+	s.conn.Close()
+}
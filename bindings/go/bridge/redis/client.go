@@ -0,0 +1,158 @@
+// Package redis republishes jasonisnthappy watch events onto Redis Streams
+// so read-only replicas or downstream services can react to changes
+// without each opening the same on-disk database, which the core does not
+// support concurrently.
+//
+// This package speaks just enough of the RESP protocol for XADD/XREAD/
+// XGROUP/XACK; it intentionally avoids pulling in a full Redis client since
+// this module ships no dependency manifest of its own.
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// conn is a minimal RESP (REdis Serialization Protocol) client connection.
+type conn struct {
+	nc net.Conn
+	r  *bufio.Reader
+}
+
+func dial(redisURL string) (*conn, error) {
+	addr, err := parseAddr(redisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	nc, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("redis: dial %s: %w", addr, err)
+	}
+
+	return &conn{nc: nc, r: bufio.NewReader(nc)}, nil
+}
+
+func parseAddr(redisURL string) (string, error) {
+	u, err := url.Parse(redisURL)
+	if err != nil {
+		return "", fmt.Errorf("redis: invalid url %q: %w", redisURL, err)
+	}
+	host := u.Host
+	if host == "" {
+		host = redisURL // allow bare host:port for convenience
+	}
+	if !strings.Contains(host, ":") {
+		host += ":6379"
+	}
+	return host, nil
+}
+
+func (c *conn) Close() error {
+	return c.nc.Close()
+}
+
+// do sends a RESP array command and returns the raw reply.
+func (c *conn) do(args ...string) (reply, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := c.nc.Write([]byte(b.String())); err != nil {
+		return reply{}, err
+	}
+	return readReply(c.r)
+}
+
+// replyType mirrors the RESP type prefixes this client understands.
+type replyType byte
+
+const (
+	replySimpleString replyType = '+'
+	replyError        replyType = '-'
+	replyInteger      replyType = ':'
+	replyBulkString   replyType = '$'
+	replyArray        replyType = '*'
+)
+
+type reply struct {
+	typ   replyType
+	str   string
+	items []reply
+	isNil bool
+}
+
+func readReply(r *bufio.Reader) (reply, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return reply{}, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) == 0 {
+		return reply{}, fmt.Errorf("redis: empty reply line")
+	}
+
+	typ := replyType(line[0])
+	body := line[1:]
+
+	switch typ {
+	case replySimpleString, replyInteger:
+		return reply{typ: typ, str: body}, nil
+
+	case replyError:
+		return reply{}, fmt.Errorf("redis: %s", body)
+
+	case replyBulkString:
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return reply{}, fmt.Errorf("redis: malformed bulk length %q", body)
+		}
+		if n < 0 {
+			return reply{typ: typ, isNil: true}, nil
+		}
+		buf := make([]byte, n+2) // payload + trailing CRLF
+		if _, err := readFull(r, buf); err != nil {
+			return reply{}, err
+		}
+		return reply{typ: typ, str: string(buf[:n])}, nil
+
+	case replyArray:
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return reply{}, fmt.Errorf("redis: malformed array length %q", body)
+		}
+		if n < 0 {
+			return reply{typ: typ, isNil: true}, nil
+		}
+		items := make([]reply, 0, n)
+		for i := 0; i < n; i++ {
+			item, err := readReply(r)
+			if err != nil {
+				return reply{}, err
+			}
+			items = append(items, item)
+		}
+		return reply{typ: typ, items: items}, nil
+
+	default:
+		return reply{}, fmt.Errorf("redis: unknown reply type %q", line)
+	}
+}
+
+func readFull(r *bufio.Reader, buf []byte) (int, error) {
+	total := 0
+	for total < len(buf) {
+		n, err := r.Read(buf[total:])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,494 @@
+package jasonisnthappy
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// BSON element type tags, per the globalsign/mgo wire format: a
+// little-endian int32 length, a list of {type byte, C-string name, value}
+// elements, terminated by a 0x00 byte.
+const (
+	bsonTypeDouble   byte = 0x01
+	bsonTypeString   byte = 0x02
+	bsonTypeDocument byte = 0x03
+	bsonTypeArray    byte = 0x04
+	bsonTypeBinary   byte = 0x05
+	bsonTypeObjectID byte = 0x07
+	bsonTypeBool     byte = 0x08
+	bsonTypeDatetime byte = 0x09
+	bsonTypeNull     byte = 0x0A
+	bsonTypeInt32    byte = 0x10
+	bsonTypeInt64    byte = 0x12
+)
+
+const bsonSubtypeGeneric byte = 0x00
+
+// ObjectID is a 12-byte BSON ObjectId, laid out exactly as the
+// globalsign/mgo wire format expects it, so it round-trips through
+// BSONCodec verbatim.
+type ObjectID [12]byte
+
+type bsonCodec struct{}
+
+// BSONCodec encodes documents using the BSON wire format (the same one
+// globalsign/mgo produces) instead of JSON. Unlike JSON it round-trips
+// time.Time, []byte, ObjectID, and 64-bit integers without lossy coercion,
+// at the cost of only understanding the Go types this package knows how to
+// encode: maps, structs, slices/arrays, strings, bools, the numeric kinds,
+// time.Time, []byte and ObjectID.
+var BSONCodec Codec = bsonCodec{}
+
+func (bsonCodec) ContentType() string { return "application/bson" }
+
+func (bsonCodec) Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("bson: cannot marshal nil %s", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	return bsonEncodeDocument(rv)
+}
+
+func (bsonCodec) Unmarshal(data []byte, v interface{}) error {
+	doc, _, err := bsonDecodeDocument(data)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("bson: Unmarshal target must be a non-nil pointer")
+	}
+	return bsonAssign(reflect.ValueOf(doc), rv.Elem())
+}
+
+// bsonEncodeDocument encodes rv (a map or struct) as a BSON document:
+// int32 length, elements, terminating 0x00.
+func bsonEncodeDocument(rv reflect.Value) ([]byte, error) {
+	var elems []byte
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("bson: map key must be string, got %s", rv.Type().Key())
+		}
+		for _, key := range rv.MapKeys() {
+			b, err := bsonEncodeElement(key.String(), rv.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, b...)
+		}
+	case reflect.Struct:
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" { // unexported
+				continue
+			}
+			name, omitempty, skip := bsonFieldName(field)
+			if skip {
+				continue
+			}
+			fv := rv.Field(i)
+			if omitempty && fv.IsZero() {
+				continue
+			}
+			b, err := bsonEncodeElement(name, fv)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, b...)
+		}
+	default:
+		return nil, fmt.Errorf("bson: cannot marshal %s as a document", rv.Kind())
+	}
+
+	buf := make([]byte, 4, len(elems)+5)
+	buf = append(buf, elems...)
+	buf = append(buf, 0x00)
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)))
+	return buf, nil
+}
+
+// bsonFieldName mirrors encoding/json's struct tag conventions (falling
+// back to the "bson" tag, then "json", then the field name) so structs
+// already tagged for this package's JSON codec work unchanged with BSON.
+func bsonFieldName(field reflect.StructField) (name string, omitempty, skip bool) {
+	tag := field.Tag.Get("bson")
+	if tag == "" {
+		tag = field.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := splitTag(tag)
+	name = field.Name
+	if parts[0] != "" {
+		name = parts[0]
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+func splitTag(tag string) []string {
+	if tag == "" {
+		return []string{""}
+	}
+	var parts []string
+	start := 0
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == ',' {
+			parts = append(parts, tag[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, tag[start:])
+	return parts
+}
+
+func bsonEncodeElement(name string, rv reflect.Value) ([]byte, error) {
+	for rv.Kind() == reflect.Interface {
+		rv = rv.Elem()
+	}
+
+	var typ byte
+	var value []byte
+	var err error
+
+	switch {
+	case !rv.IsValid():
+		typ, value = bsonTypeNull, nil
+	case rv.Type() == reflect.TypeOf(ObjectID{}):
+		typ = bsonTypeObjectID
+		id := rv.Interface().(ObjectID)
+		value = id[:]
+	case rv.Type() == reflect.TypeOf(time.Time{}):
+		typ = bsonTypeDatetime
+		value = make([]byte, 8)
+		ms := rv.Interface().(time.Time).UnixNano() / int64(time.Millisecond)
+		binary.LittleEndian.PutUint64(value, uint64(ms))
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		typ = bsonTypeBinary
+		data := rv.Bytes()
+		value = make([]byte, 0, 5+len(data))
+		var lenBuf [4]byte
+		binary.LittleEndian.PutUint32(lenBuf[:], uint32(len(data)))
+		value = append(value, lenBuf[:]...)
+		value = append(value, bsonSubtypeGeneric)
+		value = append(value, data...)
+	case rv.Kind() == reflect.Ptr:
+		if rv.IsNil() {
+			typ, value = bsonTypeNull, nil
+		} else {
+			return bsonEncodeElement(name, rv.Elem())
+		}
+	case rv.Kind() == reflect.String:
+		typ = bsonTypeString
+		value = bsonEncodeString(rv.String())
+	case rv.Kind() == reflect.Bool:
+		typ = bsonTypeBool
+		if rv.Bool() {
+			value = []byte{0x01}
+		} else {
+			value = []byte{0x00}
+		}
+	case rv.Kind() == reflect.Int32 || rv.Kind() == reflect.Int16 || rv.Kind() == reflect.Int8:
+		typ = bsonTypeInt32
+		value = make([]byte, 4)
+		binary.LittleEndian.PutUint32(value, uint32(rv.Int()))
+	case rv.Kind() == reflect.Int64 || rv.Kind() == reflect.Int:
+		typ = bsonTypeInt64
+		value = make([]byte, 8)
+		binary.LittleEndian.PutUint64(value, uint64(rv.Int()))
+	case rv.Kind() == reflect.Uint || rv.Kind() == reflect.Uint8 || rv.Kind() == reflect.Uint16 ||
+		rv.Kind() == reflect.Uint32 || rv.Kind() == reflect.Uint64:
+		typ = bsonTypeInt64
+		value = make([]byte, 8)
+		binary.LittleEndian.PutUint64(value, rv.Uint())
+	case rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64:
+		typ = bsonTypeDouble
+		value = make([]byte, 8)
+		binary.LittleEndian.PutUint64(value, math.Float64bits(rv.Float()))
+	case rv.Kind() == reflect.Map || rv.Kind() == reflect.Struct:
+		typ = bsonTypeDocument
+		value, err = bsonEncodeDocument(rv)
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		typ = bsonTypeArray
+		value, err = bsonEncodeArray(rv)
+	default:
+		return nil, fmt.Errorf("bson: unsupported type %s for field %q", rv.Kind(), name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 2+len(name)+len(value))
+	out = append(out, typ)
+	out = append(out, bsonEncodeCString(name)...)
+	out = append(out, value...)
+	return out, nil
+}
+
+func bsonEncodeArray(rv reflect.Value) ([]byte, error) {
+	var elems []byte
+	for i := 0; i < rv.Len(); i++ {
+		b, err := bsonEncodeElement(fmt.Sprintf("%d", i), rv.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, b...)
+	}
+	buf := make([]byte, 4, len(elems)+5)
+	buf = append(buf, elems...)
+	buf = append(buf, 0x00)
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)))
+	return buf, nil
+}
+
+func bsonEncodeCString(s string) []byte {
+	out := make([]byte, 0, len(s)+1)
+	out = append(out, s...)
+	return append(out, 0x00)
+}
+
+// bsonEncodeString encodes a BSON "string" value: int32 length (the string
+// byte count plus the terminating null) followed by the bytes and the null.
+func bsonEncodeString(s string) []byte {
+	out := make([]byte, 4, 5+len(s))
+	out = append(out, s...)
+	out = append(out, 0x00)
+	binary.LittleEndian.PutUint32(out, uint32(len(s)+1))
+	return out
+}
+
+// bsonDecodeDocument decodes a BSON document into a map[string]interface{},
+// returning the number of bytes it consumed from data.
+func bsonDecodeDocument(data []byte) (map[string]interface{}, int, error) {
+	if len(data) < 5 {
+		return nil, 0, fmt.Errorf("bson: document too short (%d bytes)", len(data))
+	}
+	length := int(binary.LittleEndian.Uint32(data))
+	if length < 5 || length > len(data) {
+		return nil, 0, fmt.Errorf("bson: invalid document length %d", length)
+	}
+
+	doc := make(map[string]interface{})
+	pos := 4
+	for pos < length-1 {
+		typ := data[pos]
+		pos++
+
+		name, n, err := bsonReadCString(data[pos:])
+		if err != nil {
+			return nil, 0, err
+		}
+		pos += n
+
+		value, n, err := bsonDecodeValue(typ, data[pos:])
+		if err != nil {
+			return nil, 0, err
+		}
+		pos += n
+
+		doc[name] = value
+	}
+
+	return doc, length, nil
+}
+
+// bsonDecodeArray decodes a BSON array (wire-identical to a document whose
+// keys are "0", "1", ...) into a []interface{}, preserving key order by
+// sorting numerically.
+func bsonDecodeArray(data []byte) ([]interface{}, int, error) {
+	doc, n, err := bsonDecodeDocument(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	arr := make([]interface{}, len(doc))
+	for i := range arr {
+		arr[i] = doc[fmt.Sprintf("%d", i)]
+	}
+	return arr, n, nil
+}
+
+func bsonReadCString(data []byte) (string, int, error) {
+	for i, b := range data {
+		if b == 0x00 {
+			return string(data[:i]), i + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("bson: unterminated cstring")
+}
+
+func bsonDecodeValue(typ byte, data []byte) (interface{}, int, error) {
+	switch typ {
+	case bsonTypeDouble:
+		if len(data) < 8 {
+			return nil, 0, fmt.Errorf("bson: truncated double")
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), 8, nil
+	case bsonTypeString:
+		if len(data) < 4 {
+			return nil, 0, fmt.Errorf("bson: truncated string")
+		}
+		n := int(binary.LittleEndian.Uint32(data))
+		if n < 1 || 4+n > len(data) {
+			return nil, 0, fmt.Errorf("bson: invalid string length %d", n)
+		}
+		return string(data[4 : 4+n-1]), 4 + n, nil
+	case bsonTypeDocument:
+		doc, n, err := bsonDecodeDocument(data)
+		return doc, n, err
+	case bsonTypeArray:
+		arr, n, err := bsonDecodeArray(data)
+		return arr, n, err
+	case bsonTypeBinary:
+		if len(data) < 5 {
+			return nil, 0, fmt.Errorf("bson: truncated binary")
+		}
+		n := int(binary.LittleEndian.Uint32(data))
+		if n < 0 || 5+n > len(data) {
+			return nil, 0, fmt.Errorf("bson: invalid binary length %d", n)
+		}
+		out := make([]byte, n)
+		copy(out, data[5:5+n])
+		return out, 5 + n, nil
+	case bsonTypeObjectID:
+		if len(data) < 12 {
+			return nil, 0, fmt.Errorf("bson: truncated ObjectId")
+		}
+		var id ObjectID
+		copy(id[:], data[:12])
+		return id, 12, nil
+	case bsonTypeBool:
+		if len(data) < 1 {
+			return nil, 0, fmt.Errorf("bson: truncated bool")
+		}
+		return data[0] != 0x00, 1, nil
+	case bsonTypeDatetime:
+		if len(data) < 8 {
+			return nil, 0, fmt.Errorf("bson: truncated datetime")
+		}
+		ms := int64(binary.LittleEndian.Uint64(data))
+		return time.UnixMilli(ms).UTC(), 8, nil
+	case bsonTypeNull:
+		return nil, 0, nil
+	case bsonTypeInt32:
+		if len(data) < 4 {
+			return nil, 0, fmt.Errorf("bson: truncated int32")
+		}
+		return int32(binary.LittleEndian.Uint32(data)), 4, nil
+	case bsonTypeInt64:
+		if len(data) < 8 {
+			return nil, 0, fmt.Errorf("bson: truncated int64")
+		}
+		return int64(binary.LittleEndian.Uint64(data)), 8, nil
+	default:
+		return nil, 0, fmt.Errorf("bson: unsupported element type 0x%02x", typ)
+	}
+}
+
+// bsonAssign copies a decoded BSON value (src, holding the types
+// bsonDecodeValue produces) into dst, converting where the two differ in
+// kind but not in the underlying representation (e.g. a decoded
+// map[string]interface{} into a struct field, or float64 into an int).
+func bsonAssign(src, dst reflect.Value) error {
+	if !src.IsValid() {
+		return nil
+	}
+	if src.Kind() == reflect.Interface {
+		src = src.Elem()
+	}
+	if !src.IsValid() {
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface:
+		dst.Set(src)
+		return nil
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return bsonAssign(src, dst.Elem())
+	}
+
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	if src.Type().ConvertibleTo(dst.Type()) && isBSONNumericKind(src.Kind()) && isBSONNumericKind(dst.Kind()) {
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+
+	switch {
+	case src.Kind() == reflect.Map && dst.Kind() == reflect.Struct:
+		t := dst.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name, _, skip := bsonFieldName(field)
+			if skip {
+				continue
+			}
+			v := src.MapIndex(reflect.ValueOf(name))
+			if !v.IsValid() {
+				continue
+			}
+			if err := bsonAssign(v, dst.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case src.Kind() == reflect.Map && dst.Kind() == reflect.Map:
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		for _, key := range src.MapKeys() {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := bsonAssign(src.MapIndex(key), elem); err != nil {
+				return err
+			}
+			dst.SetMapIndex(key, elem)
+		}
+		return nil
+	case (src.Kind() == reflect.Slice || src.Kind() == reflect.Array) && (dst.Kind() == reflect.Slice || dst.Kind() == reflect.Array):
+		if dst.Kind() == reflect.Slice {
+			dst.Set(reflect.MakeSlice(dst.Type(), src.Len(), src.Len()))
+		}
+		for i := 0; i < src.Len() && i < dst.Len(); i++ {
+			if err := bsonAssign(src.Index(i), dst.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("bson: cannot assign %s into %s", src.Type(), dst.Type())
+}
+
+func isBSONNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package jasonisnthappy
+
+import (
+	"testing"
+	"time"
+)
+
+type bsonSample struct {
+	Name  string    `json:"name"`
+	Age   int       `json:"age"`
+	Tags  []string  `json:"tags"`
+	When  time.Time `json:"when"`
+	Bytes []byte    `json:"bytes"`
+}
+
+func TestBSONCodecRoundtripStruct(t *testing.T) {
+	in := bsonSample{
+		Name:  "ada",
+		Age:   37,
+		Tags:  []string{"engineer", "mathematician"},
+		When:  time.Now().UTC().Truncate(time.Millisecond),
+		Bytes: []byte{0x01, 0x02, 0x03},
+	}
+
+	data, err := BSONCodec.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var out bsonSample
+	if err := BSONCodec.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if out.Name != in.Name || out.Age != in.Age {
+		t.Fatalf("scalar fields mismatch: got %+v, want %+v", out, in)
+	}
+	if len(out.Tags) != len(in.Tags) || out.Tags[0] != in.Tags[0] || out.Tags[1] != in.Tags[1] {
+		t.Fatalf("tags mismatch: got %v, want %v", out.Tags, in.Tags)
+	}
+	if !out.When.Equal(in.When) {
+		t.Fatalf("when mismatch: got %v, want %v", out.When, in.When)
+	}
+	if string(out.Bytes) != string(in.Bytes) {
+		t.Fatalf("bytes mismatch: got %v, want %v", out.Bytes, in.Bytes)
+	}
+}
+
+func TestBSONCodecRoundtripMap(t *testing.T) {
+	in := map[string]interface{}{
+		"score":  98.6,
+		"active": true,
+		"nested": map[string]interface{}{"a": int64(1), "b": int64(2)},
+	}
+
+	data, err := BSONCodec.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var out map[string]interface{}
+	if err := BSONCodec.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if out["score"].(float64) != 98.6 {
+		t.Fatalf("score mismatch: got %v", out["score"])
+	}
+	if out["active"].(bool) != true {
+		t.Fatalf("active mismatch: got %v", out["active"])
+	}
+	nested, ok := out["nested"].(map[string]interface{})
+	if !ok || nested["a"].(int64) != 1 || nested["b"].(int64) != 2 {
+		t.Fatalf("nested mismatch: got %v", out["nested"])
+	}
+}
+
+func TestBSONCodecContentType(t *testing.T) {
+	if BSONCodec.ContentType() != "application/bson" {
+		t.Fatalf("unexpected content type: %s", BSONCodec.ContentType())
+	}
+	if JSONCodec.ContentType() != "application/json" {
+		t.Fatalf("unexpected content type: %s", JSONCodec.ContentType())
+	}
+}
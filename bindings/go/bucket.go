@@ -0,0 +1,444 @@
+package jasonisnthappy
+
+/*
+#include <stdlib.h>
+#include "jasonisnthappy.h"
+*/
+import "C"
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+)
+
+// defaultBucketChunkSize is mgo's GridFS default chunk size.
+const defaultBucketChunkSize = 255 * 1024
+
+// bucketChunksIndex is the compound index Bucket relies on to stream a
+// file's chunks back in order: sorted by files_id, then by chunk number.
+const bucketChunksIndex = "files_id_n"
+
+// FileInfo is a document in a Bucket's "<name>.files" collection: one
+// uploaded file's metadata.
+type FileInfo struct {
+	ID          string                 `json:"_id"`
+	Length      int64                  `json:"length"`
+	ChunkSize   int                    `json:"chunkSize"`
+	UploadDate  time.Time              `json:"uploadDate"`
+	SHA256      string                 `json:"sha256,omitempty"`
+	MD5         string                 `json:"md5,omitempty"`
+	ContentType string                 `json:"contentType,omitempty"`
+	Filename    string                 `json:"filename"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// bucketChunk is a document in a Bucket's "<name>.chunks" collection: one
+// fixed-size slice of a file's bytes.
+type bucketChunk struct {
+	FilesID string `json:"files_id"`
+	N       int    `json:"n"`
+	Data    []byte `json:"data"`
+}
+
+// BucketOptions configures a Bucket.
+type BucketOptions struct {
+	// ChunkSize is the size, in bytes, of each chunk document. Defaults to
+	// 255 KiB (mgo's GridFS default) when zero.
+	ChunkSize int
+}
+
+// Bucket stores files too large for MaxDocumentSize across two ordinary
+// collections, "<name>.files" (metadata) and "<name>.chunks" (fixed-size
+// binary pieces), the same layout mgo's GridFS uses. Upload with
+// OpenUploadStream, read back with OpenDownloadStream.
+type Bucket struct {
+	db         *Database
+	filesColl  string
+	chunksColl string
+	chunkSize  int
+}
+
+// Bucket returns a GridFS-style Bucket named name, using the default chunk
+// size. The backing collections ("<name>.files", "<name>.chunks") are
+// created lazily on first upload.
+func (d *Database) Bucket(name string) (*Bucket, error) {
+	return d.BucketWithOptions(name, BucketOptions{})
+}
+
+// BucketWithOptions is like Bucket, but lets callers override the default
+// chunk size.
+func (d *Database) BucketWithOptions(name string, opts BucketOptions) (*Bucket, error) {
+	if d.db == nil {
+		return nil, &Error{Code: -1, Message: "Database is closed"}
+	}
+
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultBucketChunkSize
+	}
+
+	return &Bucket{
+		db:         d,
+		filesColl:  name + ".files",
+		chunksColl: name + ".chunks",
+		chunkSize:  chunkSize,
+	}, nil
+}
+
+// ensureChunksIndex makes sure the chunks collection has the compound
+// (files_id, n) index OpenDownloadStream relies on to stream chunks back
+// in order. Creating an index that already exists is the common case here,
+// so any error from CreateCompoundIndex is ignored; OpenDownloadStream's
+// chunk query filters on files_id and sorts on n whether or not the index
+// exists.
+func (b *Bucket) ensureChunksIndex() {
+	b.db.CreateCompoundIndex(b.chunksColl, bucketChunksIndex, []string{"files_id", "n"}, false)
+}
+
+// UploadStream accumulates an upload's bytes into fixed-size chunks. Close
+// writes every chunk plus the file's "<name>.files" record as a single
+// transaction, so a failed or never-closed upload leaves no partial file
+// behind.
+type UploadStream struct {
+	bucket      *Bucket
+	filename    string
+	metadata    map[string]interface{}
+	contentType string
+
+	id     string
+	buf    []byte
+	chunkN int
+	length int64
+	digest hash.Hash
+	md5sum hash.Hash
+	md5Hex string
+	chunks []bucketChunk
+	closed bool
+}
+
+var _ io.WriteCloser = (*UploadStream)(nil)
+
+// OpenUploadStream begins a new upload named filename, recording meta (may
+// be nil) as the resulting file's metadata. Write the file's bytes to the
+// returned stream and call Close to commit it; a stream that is never
+// closed never touches the database.
+func (b *Bucket) OpenUploadStream(filename string, meta map[string]interface{}) (*UploadStream, error) {
+	if b.db.db == nil {
+		return nil, &Error{Code: -1, Message: "Database is closed"}
+	}
+
+	id, err := newBucketFileID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadStream{
+		bucket:   b,
+		filename: filename,
+		metadata: meta,
+		id:       id,
+		digest:   sha256.New(),
+		md5sum:   md5.New(),
+	}, nil
+}
+
+// SetContentType records the MIME type stored as the file's ContentType.
+// Only meaningful before Close.
+func (u *UploadStream) SetContentType(contentType string) {
+	u.contentType = contentType
+}
+
+// SetMetadata replaces the metadata stored alongside the file, overriding
+// whatever was passed to OpenUploadStream. Only meaningful before Close.
+func (u *UploadStream) SetMetadata(meta map[string]interface{}) {
+	u.metadata = meta
+}
+
+// ID returns the ID the uploaded file will be stored under, valid as soon
+// as the stream is opened (it doesn't require Close to have run).
+func (u *UploadStream) ID() string {
+	return u.id
+}
+
+// Write buffers p and stages any complete chunkSize-byte chunks it
+// completes for Close to insert.
+func (u *UploadStream) Write(p []byte) (int, error) {
+	if u.closed {
+		return 0, &Error{Code: -1, Message: "UploadStream is closed"}
+	}
+
+	n := len(p)
+	u.length += int64(n)
+	u.digest.Write(p)
+	u.md5sum.Write(p)
+	u.buf = append(u.buf, p...)
+
+	for len(u.buf) >= u.bucket.chunkSize {
+		if err := u.stageChunk(u.buf[:u.bucket.chunkSize]); err != nil {
+			return 0, err
+		}
+		u.buf = u.buf[u.bucket.chunkSize:]
+	}
+
+	return n, nil
+}
+
+func (u *UploadStream) stageChunk(data []byte) error {
+	chunkData := make([]byte, len(data))
+	copy(chunkData, data)
+
+	u.chunks = append(u.chunks, bucketChunk{
+		FilesID: u.id,
+		N:       u.chunkN,
+		Data:    chunkData,
+	})
+	u.chunkN++
+	return nil
+}
+
+// Close flushes any buffered bytes, then writes all of the file's chunks
+// and its "<name>.files" record atomically. Calling Close more than once
+// is a no-op.
+func (u *UploadStream) Close() error {
+	if u.closed {
+		return nil
+	}
+	u.closed = true
+
+	if len(u.buf) > 0 {
+		if err := u.stageChunk(u.buf); err != nil {
+			return err
+		}
+		u.buf = nil
+	}
+
+	u.bucket.ensureChunksIndex()
+
+	tx, err := u.bucket.db.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, chunk := range u.chunks {
+		if _, err := tx.Insert(u.bucket.chunksColl, chunk); err != nil {
+			return err
+		}
+	}
+
+	u.md5Hex = hex.EncodeToString(u.md5sum.Sum(nil))
+
+	file := FileInfo{
+		ID:          u.id,
+		Length:      u.length,
+		ChunkSize:   u.bucket.chunkSize,
+		UploadDate:  time.Now().UTC(),
+		SHA256:      hex.EncodeToString(u.digest.Sum(nil)),
+		MD5:         u.md5Hex,
+		ContentType: u.contentType,
+		Filename:    u.filename,
+		Metadata:    u.metadata,
+	}
+
+	if _, err := tx.Insert(u.bucket.filesColl, file); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DownloadStream reads a file's bytes back out in chunk order. The chunks
+// matching the file are fetched from the (files_id, n) index in a single
+// query when the stream is opened, since there's no streaming cursor to
+// fetch them lazily.
+type DownloadStream struct {
+	bucket *Bucket
+	file   FileInfo
+	chunks []bucketChunk
+
+	chunk []byte
+	pos   int64
+	next  int
+	done  bool
+	err   error
+}
+
+var _ io.ReadCloser = (*DownloadStream)(nil)
+var _ io.ReaderAt = (*DownloadStream)(nil)
+
+// OpenDownloadStream opens a DownloadStream over the file stored under id.
+func (b *Bucket) OpenDownloadStream(id string) (*DownloadStream, error) {
+	if b.db.db == nil {
+		return nil, &Error{Code: -1, Message: "Database is closed"}
+	}
+
+	b.ensureChunksIndex()
+
+	filesColl, err := b.db.GetCollection(b.filesColl)
+	if err != nil {
+		return nil, err
+	}
+	defer filesColl.Free()
+
+	var file FileInfo
+	found, err := filesColl.FindByID(id, &file)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &Error{Code: -1, Message: fmt.Sprintf("bucket: no file with id %q", id)}
+	}
+
+	chunksColl, err := b.db.GetCollection(b.chunksColl)
+	if err != nil {
+		return nil, err
+	}
+	defer chunksColl.Free()
+
+	var chunks []bucketChunk
+	filter := fmt.Sprintf(`{"files_id": %q}`, id)
+	if err := chunksColl.QueryWithOptions(filter, "n", true, 0, 0, nil, nil, &chunks); err != nil {
+		return nil, err
+	}
+
+	return &DownloadStream{bucket: b, file: file, chunks: chunks}, nil
+}
+
+// FileInfo returns the metadata of the file this stream is reading.
+func (d *DownloadStream) FileInfo() FileInfo {
+	return d.file
+}
+
+// Read implements io.Reader, returning the file's bytes in order across
+// however many chunks they were split into.
+func (d *DownloadStream) Read(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	total := 0
+	for total < len(p) {
+		if len(d.chunk) == 0 {
+			if !d.fetchNextChunk() {
+				break
+			}
+		}
+
+		n := copy(p[total:], d.chunk)
+		d.chunk = d.chunk[n:]
+		total += n
+	}
+
+	d.pos += int64(total)
+
+	if total == 0 && d.done {
+		return 0, io.EOF
+	}
+	return total, d.err
+}
+
+// fetchNextChunk advances to the next chunk belonging to this file.
+func (d *DownloadStream) fetchNextChunk() bool {
+	if d.done {
+		return false
+	}
+
+	if d.next >= len(d.chunks) {
+		d.done = true
+		return false
+	}
+
+	d.chunk = d.chunks[d.next].Data
+	d.next++
+	return true
+}
+
+// ReadAt implements io.ReaderAt by walking the file's chunks from the
+// start; GridFS files are typically read sequentially or in a handful of
+// large windows, so this isn't optimized for random access.
+func (d *DownloadStream) ReadAt(p []byte, off int64) (int, error) {
+	fresh, err := d.bucket.OpenDownloadStream(d.file.ID)
+	if err != nil {
+		return 0, err
+	}
+	defer fresh.Close()
+
+	if _, err := io.CopyN(io.Discard, fresh, off); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for n < len(p) {
+		m, err := fresh.Read(p[n:])
+		n += m
+		if err != nil {
+			if err == io.EOF && n > 0 {
+				return n, nil
+			}
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Close releases the stream's buffered chunks.
+func (d *DownloadStream) Close() error {
+	d.chunks = nil
+	return nil
+}
+
+// Delete removes a file and all of its chunks.
+func (b *Bucket) Delete(id string) error {
+	filesColl, err := b.db.GetCollection(b.filesColl)
+	if err != nil {
+		return err
+	}
+	defer filesColl.Free()
+
+	if _, err := filesColl.DeleteOne(fmt.Sprintf(`{"_id": %q}`, id)); err != nil {
+		return err
+	}
+
+	chunksColl, err := b.db.GetCollection(b.chunksColl)
+	if err != nil {
+		return err
+	}
+	defer chunksColl.Free()
+
+	_, err = chunksColl.Delete(fmt.Sprintf(`{"files_id": %q}`, id))
+	return err
+}
+
+// Find returns the file metadata matching filter (the same query-object
+// syntax Collection.Find accepts).
+func (b *Bucket) Find(filter string) ([]FileInfo, error) {
+	if b.db.db == nil {
+		return nil, &Error{Code: -1, Message: "Database is closed"}
+	}
+
+	filesColl, err := b.db.GetCollection(b.filesColl)
+	if err != nil {
+		return nil, err
+	}
+	defer filesColl.Free()
+
+	var files []FileInfo
+	if err := filesColl.Find(filter, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func newBucketFileID() (string, error) {
+	var raw [12]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw[:]), nil
+}
@@ -2,18 +2,20 @@
 // Downloads the native static library to the module cache
 //
 // Usage:
-//   go run github.com/sohzm/jasonisnthappy/bindings/go/cmd/install@latest
+//
+//	go run github.com/sohzm/jasonisnthappy/bindings/go/cmd/install@latest
 package main
 
 import (
+	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"github.com/sohzm/jasonisnthappy/bindings/go/internal/installutil"
 )
 
 const (
@@ -22,18 +24,105 @@ const (
 	module  = "github.com/sohzm/jasonisnthappy/bindings/go"
 )
 
+var (
+	insecure    = flag.Bool("insecure", false, "skip checksum verification of the downloaded library")
+	osFlag      = flag.String("os", "", "target GOOS to install for (defaults to $GOOS, then the host OS)")
+	archFlag    = flag.String("arch", "", "target GOARCH to install for (defaults to $GOARCH, then the host arch)")
+	all         = flag.Bool("all", false, "install the library for every supported GOOS/GOARCH pair")
+	targetsFlag = flag.String("targets", "", "comma-separated GOOS/GOARCH pairs to install, e.g. darwin/arm64,linux/amd64")
+	proxyFlag   = flag.String("proxy", "", "comma-separated mirror list to try before the canonical release URL (see $JASONISNTHAPPY_PROXY)")
+)
+
+// allPlatforms lists every goos/goarch pair this installer knows how to
+// fetch a static archive for, used by --all and --targets.
+var allPlatforms = []struct{ goos, arch string }{
+	{"darwin", "arm64"},
+	{"darwin", "amd64"},
+	{"linux", "arm64"},
+	{"linux", "amd64"},
+	{"windows", "amd64"},
+}
+
 func main() {
-	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	flag.Parse()
+
+	var targets []struct{ goos, arch string }
+	switch {
+	case *targetsFlag != "":
+		var err error
+		targets, err = parseTargets(*targetsFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	case *all:
+		targets = allPlatforms
+	default:
+		goos := *osFlag
+		if goos == "" {
+			goos = envDefault("GOOS", runtime.GOOS)
+		}
+		goarch := *archFlag
+		if goarch == "" {
+			goarch = envDefault("GOARCH", runtime.GOARCH)
+		}
+		targets = []struct{ goos, arch string }{{goos, goarch}}
+	}
+
+	failed := false
+	for _, t := range targets {
+		if err := run(t.goos, t.arch); err != nil {
+			fmt.Fprintf(os.Stderr, "Error installing %s/%s: %v\n", t.goos, t.arch, err)
+			failed = true
+		}
+	}
+	if failed {
 		os.Exit(1)
 	}
 }
 
-func run() error {
+// envDefault returns the GOOS/GOARCH env var value when set, falling
+// back to the host's own runtime.GOOS/runtime.GOARCH otherwise, the same
+// precedence `go build` itself uses for cross-compilation.
+func envDefault(envVar, hostValue string) string {
+	if v := os.Getenv(envVar); v != "" {
+		return v
+	}
+	return hostValue
+}
+
+// proxyList returns the configured JASONISNTHAPPY_PROXY mirror list,
+// falling back to proxyFlag when the env var isn't set.
+func proxyList(proxyFlag string) string {
+	if v := os.Getenv("JASONISNTHAPPY_PROXY"); v != "" {
+		return v
+	}
+	return proxyFlag
+}
+
+// parseTargets parses a "--targets" value like
+// "darwin/arm64,linux/amd64,windows/amd64" into goos/goarch pairs.
+func parseTargets(targets string) ([]struct{ goos, arch string }, error) {
+	var out []struct{ goos, arch string }
+	for _, t := range strings.Split(targets, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		parts := strings.SplitN(t, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid target %q, expected GOOS/GOARCH", t)
+		}
+		out = append(out, struct{ goos, arch string }{parts[0], parts[1]})
+	}
+	return out, nil
+}
+
+func run(goos, goarch string) error {
 	// Determine platform
-	platform := getPlatform()
+	platform := getPlatform(goos, goarch)
 	if platform == "" {
-		return fmt.Errorf("unsupported platform: %s/%s", runtime.GOOS, runtime.GOARCH)
+		return fmt.Errorf("unsupported platform: %s/%s", goos, goarch)
 	}
 
 	// Find module cache path
@@ -56,40 +145,74 @@ func run() error {
 	}
 
 	// Download static library
+	artifact := platform + "-static.a"
 	libPath := filepath.Join(libDir, "libjasonisnthappy.a")
-	url := fmt.Sprintf("%s/%s-static.a", baseURL, platform)
+	directURL := fmt.Sprintf("%s/%s", baseURL, artifact)
+	sources := installutil.ResolveSources(proxyList(*proxyFlag), platform, artifact, directURL)
 
 	fmt.Printf("📥 Downloading static library for %s...\n", platform)
-	fmt.Printf("   URL: %s\n", url)
+	fmt.Printf("   URL: %s\n", directURL)
+
+	var sums map[string]string
+	if !*insecure {
+		sums, err = loadSums(modPath)
+		if err != nil {
+			return err
+		}
+	}
 
-	if err := downloadFile(url, libPath); err != nil {
+	if err := installutil.Install(sources, libPath, installutil.Options{
+		Insecure: *insecure,
+		Sums:     sums,
+		SumKey:   artifact,
+	}); err != nil {
 		return fmt.Errorf("downloading library: %w", err)
 	}
 
+	if *insecure {
+		fmt.Println("⚠ --insecure set, skipping checksum verification")
+	} else {
+		fmt.Println("✓ Checksum verified")
+	}
+
 	fmt.Printf("✓ Successfully installed to %s\n", libPath)
 	fmt.Println("✓ You can now run 'go build' in your project")
 
 	return nil
 }
 
-func getPlatform() string {
-	switch runtime.GOOS {
+// loadSums reads the libraries.sum manifest that ships in modPath
+// alongside the rest of the binding module's source.
+func loadSums(modPath string) (map[string]string, error) {
+	data, err := os.ReadFile(filepath.Join(modPath, "libraries.sum"))
+	if err != nil {
+		return nil, fmt.Errorf("reading libraries.sum: %w", err)
+	}
+	sums, err := installutil.ParseSums(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing libraries.sum: %w", err)
+	}
+	return sums, nil
+}
+
+func getPlatform(goos, goarch string) string {
+	switch goos {
 	case "darwin":
-		switch runtime.GOARCH {
+		switch goarch {
 		case "arm64":
 			return "darwin-arm64"
 		case "amd64":
 			return "darwin-amd64"
 		}
 	case "linux":
-		switch runtime.GOARCH {
+		switch goarch {
 		case "arm64":
 			return "linux-arm64"
 		case "amd64":
 			return "linux-amd64"
 		}
 	case "windows":
-		if runtime.GOARCH == "amd64" {
+		if goarch == "amd64" {
 			return "windows-amd64"
 		}
 	}
@@ -140,24 +263,3 @@ func makeWritable(path string) error {
 		return os.Chmod(p, mode|0600)
 	})
 }
-
-func downloadFile(url, dest string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	out, err := os.Create(dest)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	return err
-}
@@ -0,0 +1,85 @@
+// Command jsnh runs a jasonisnthappy database as a standalone HTTP service.
+//
+// Usage:
+//
+//	jsnh serve --db path/to.db --addr 127.0.0.1:8080 [--jwt-pub key.pem]
+package main
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	jasonisnthappy "github.com/sohzm/jasonisnthappy/bindings/go"
+	"github.com/sohzm/jasonisnthappy/bindings/go/server"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "serve" {
+		fmt.Fprintln(os.Stderr, "usage: jsnh serve --db path [--addr host:port] [--jwt-pub key.pem]")
+		os.Exit(1)
+	}
+
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	dbPath := fs.String("db", "", "path to the database file (required)")
+	addr := fs.String("addr", "127.0.0.1:8080", "address to listen on")
+	jwtPub := fs.String("jwt-pub", "", "PEM-encoded RSA public key used to verify bearer tokens")
+	tlsCert := fs.String("tls-cert", "", "TLS certificate file")
+	tlsKey := fs.String("tls-key", "", "TLS key file")
+	fs.Parse(os.Args[2:])
+
+	if *dbPath == "" {
+		log.Fatal("--db is required")
+	}
+
+	db, err := jasonisnthappy.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	opts := server.Options{
+		TLSCertFile: *tlsCert,
+		TLSKeyFile:  *tlsKey,
+	}
+	if *jwtPub != "" {
+		pub, err := loadRSAPublicKey(*jwtPub)
+		if err != nil {
+			log.Fatalf("failed to load --jwt-pub %s: %v", *jwtPub, err)
+		}
+		opts.Auth = &server.JWTAuth{PublicKey: pub}
+	}
+
+	srv := server.New(db, opts)
+	log.Printf("jsnh serving %s on %s", *dbPath, *addr)
+	log.Fatal(srv.ListenAndServe(*addr))
+}
+
+// loadRSAPublicKey reads a PEM-encoded PKIX RSA public key from path, the
+// format "openssl rsa -pubout" produces.
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA public key")
+	}
+	return pub, nil
+}
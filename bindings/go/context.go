@@ -0,0 +1,342 @@
+package jasonisnthappy
+
+import "context"
+
+// runWithContext runs fn on a goroutine and returns as soon as either fn
+// completes or ctx is done, whichever comes first. There is no
+// jasonisnthappy_cancel FFI hook to abort an in-flight CGo call, so a
+// timed-out or cancelled call's goroutine keeps running to completion in
+// the background and its result is simply discarded.
+func runWithContext(ctx context.Context, fn func() error) error {
+	if ctx == nil || ctx.Done() == nil {
+		return fn()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// contextForCall applies c.db's default timeout (Database.SetDefaultTimeout)
+// to ctx when ctx carries no deadline of its own.
+func (c *Collection) contextForCall(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, hasDeadline := ctx.Deadline(); hasDeadline || c.db == nil {
+		return ctx, func() {}
+	}
+
+	timeout := c.db.defaultTimeoutDuration()
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// InsertWithContext is like Insert, but returns ctx.Err() once ctx is
+// cancelled or its deadline passes, instead of blocking until the insert
+// completes.
+func (c *Collection) InsertWithContext(ctx context.Context, doc interface{}) (string, error) {
+	var id string
+	err := runWithContext(ctx, func() error {
+		var err error
+		id, err = c.Insert(doc)
+		return err
+	})
+	return id, err
+}
+
+// InsertManyWithContext is the context-aware variant of InsertMany.
+func (c *Collection) InsertManyWithContext(ctx context.Context, docs []interface{}) ([]string, error) {
+	var ids []string
+	err := runWithContext(ctx, func() error {
+		var err error
+		ids, err = c.InsertMany(docs)
+		return err
+	})
+	return ids, err
+}
+
+// FindByIDWithContext is the context-aware variant of FindByID.
+func (c *Collection) FindByIDWithContext(ctx context.Context, id string, result interface{}) (bool, error) {
+	var found bool
+	err := runWithContext(ctx, func() error {
+		var err error
+		found, err = c.FindByID(id, result)
+		return err
+	})
+	return found, err
+}
+
+// FindAllWithContext is the context-aware variant of FindAll.
+func (c *Collection) FindAllWithContext(ctx context.Context, result interface{}) error {
+	return runWithContext(ctx, func() error {
+		return c.FindAll(result)
+	})
+}
+
+// FindWithContext is the context-aware variant of Find.
+func (c *Collection) FindWithContext(ctx context.Context, filter string, result interface{}) error {
+	return runWithContext(ctx, func() error {
+		return c.Find(filter, result)
+	})
+}
+
+// FindOneWithContext is the context-aware variant of FindOne.
+func (c *Collection) FindOneWithContext(ctx context.Context, filter string, result interface{}) (bool, error) {
+	var found bool
+	err := runWithContext(ctx, func() error {
+		var err error
+		found, err = c.FindOne(filter, result)
+		return err
+	})
+	return found, err
+}
+
+// UpdateByIDWithContext is the context-aware variant of UpdateByID.
+func (c *Collection) UpdateByIDWithContext(ctx context.Context, id string, updates interface{}) error {
+	return runWithContext(ctx, func() error {
+		return c.UpdateByID(id, updates)
+	})
+}
+
+// UpdateWithContext is the context-aware variant of Update.
+func (c *Collection) UpdateWithContext(ctx context.Context, filter string, update interface{}) (uint64, error) {
+	var n uint64
+	err := runWithContext(ctx, func() error {
+		var err error
+		n, err = c.Update(filter, update)
+		return err
+	})
+	return n, err
+}
+
+// UpdateOneWithContext is the context-aware variant of UpdateOne.
+func (c *Collection) UpdateOneWithContext(ctx context.Context, filter string, update interface{}) (bool, error) {
+	var matched bool
+	err := runWithContext(ctx, func() error {
+		var err error
+		matched, err = c.UpdateOne(filter, update)
+		return err
+	})
+	return matched, err
+}
+
+// DeleteByIDWithContext is the context-aware variant of DeleteByID.
+func (c *Collection) DeleteByIDWithContext(ctx context.Context, id string) error {
+	return runWithContext(ctx, func() error {
+		return c.DeleteByID(id)
+	})
+}
+
+// DeleteWithContext is the context-aware variant of Delete.
+func (c *Collection) DeleteWithContext(ctx context.Context, filter string) (uint64, error) {
+	var n uint64
+	err := runWithContext(ctx, func() error {
+		var err error
+		n, err = c.Delete(filter)
+		return err
+	})
+	return n, err
+}
+
+// DeleteOneWithContext is the context-aware variant of DeleteOne.
+func (c *Collection) DeleteOneWithContext(ctx context.Context, filter string) (bool, error) {
+	var deleted bool
+	err := runWithContext(ctx, func() error {
+		var err error
+		deleted, err = c.DeleteOne(filter)
+		return err
+	})
+	return deleted, err
+}
+
+// UpsertByIDWithContext is the context-aware variant of UpsertByID.
+func (c *Collection) UpsertByIDWithContext(ctx context.Context, id string, doc interface{}) (*UpsertResult, error) {
+	var res *UpsertResult
+	err := runWithContext(ctx, func() error {
+		var err error
+		res, err = c.UpsertByID(id, doc)
+		return err
+	})
+	return res, err
+}
+
+// UpsertWithContext is the context-aware variant of Upsert.
+func (c *Collection) UpsertWithContext(ctx context.Context, filter string, doc interface{}) (*UpsertResult, error) {
+	var res *UpsertResult
+	err := runWithContext(ctx, func() error {
+		var err error
+		res, err = c.Upsert(filter, doc)
+		return err
+	})
+	return res, err
+}
+
+// CountWithContext is the context-aware variant of Count.
+func (c *Collection) CountWithContext(ctx context.Context) (uint64, error) {
+	var n uint64
+	err := runWithContext(ctx, func() error {
+		var err error
+		n, err = c.Count()
+		return err
+	})
+	return n, err
+}
+
+// CountWithQueryWithContext is the context-aware variant of CountWithQuery.
+func (c *Collection) CountWithQueryWithContext(ctx context.Context, filter string) (uint64, error) {
+	var n uint64
+	err := runWithContext(ctx, func() error {
+		var err error
+		n, err = c.CountWithQuery(filter)
+		return err
+	})
+	return n, err
+}
+
+// SearchWithContext is the context-aware variant of Search.
+func (c *Collection) SearchWithContext(ctx context.Context, query string, result interface{}) error {
+	return runWithContext(ctx, func() error {
+		return c.Search(query, result)
+	})
+}
+
+// QueryWithOptionsWithContext is the context-aware variant of
+// QueryWithOptions.
+func (c *Collection) QueryWithOptionsWithContext(
+	ctx context.Context,
+	filter string,
+	sortField string,
+	sortAsc bool,
+	limit uint64,
+	skip uint64,
+	projectFields []string,
+	excludeFields []string,
+	result interface{},
+) error {
+	return runWithContext(ctx, func() error {
+		return c.QueryWithOptions(filter, sortField, sortAsc, limit, skip, projectFields, excludeFields, result)
+	})
+}
+
+// InsertWithContext is the context-aware variant of Transaction.Insert.
+func (t *Transaction) InsertWithContext(ctx context.Context, collectionName string, doc interface{}) (string, error) {
+	var id string
+	err := runWithContext(ctx, func() error {
+		var err error
+		id, err = t.Insert(collectionName, doc)
+		return err
+	})
+	return id, err
+}
+
+// FindByIDWithContext is the context-aware variant of Transaction.FindByID.
+func (t *Transaction) FindByIDWithContext(ctx context.Context, collectionName, id string, result interface{}) (bool, error) {
+	var found bool
+	err := runWithContext(ctx, func() error {
+		var err error
+		found, err = t.FindByID(collectionName, id, result)
+		return err
+	})
+	return found, err
+}
+
+// UpdateByIDWithContext is the context-aware variant of
+// Transaction.UpdateByID.
+func (t *Transaction) UpdateByIDWithContext(ctx context.Context, collectionName, id string, doc interface{}) error {
+	return runWithContext(ctx, func() error {
+		return t.UpdateByID(collectionName, id, doc)
+	})
+}
+
+// DeleteByIDWithContext is the context-aware variant of
+// Transaction.DeleteByID.
+func (t *Transaction) DeleteByIDWithContext(ctx context.Context, collectionName, id string) error {
+	return runWithContext(ctx, func() error {
+		return t.DeleteByID(collectionName, id)
+	})
+}
+
+// FindAllWithContext is the context-aware variant of Transaction.FindAll.
+func (t *Transaction) FindAllWithContext(ctx context.Context, collectionName string, result interface{}) error {
+	return runWithContext(ctx, func() error {
+		return t.FindAll(collectionName, result)
+	})
+}
+
+// CountWithContext is the context-aware variant of Transaction.Count.
+func (t *Transaction) CountWithContext(ctx context.Context, collectionName string) (uint64, error) {
+	var n uint64
+	err := runWithContext(ctx, func() error {
+		var err error
+		n, err = t.Count(collectionName)
+		return err
+	})
+	return n, err
+}
+
+// CommitWithContext is the context-aware variant of Transaction.Commit.
+func (t *Transaction) CommitWithContext(ctx context.Context) error {
+	return runWithContext(ctx, func() error {
+		return t.Commit()
+	})
+}
+
+// RunTransactionWithContext is the context-aware variant of
+// Database.RunTransaction: ctx is checked before each retry attempt and
+// during backoff sleeps, so a cancelled or expired context stops further
+// retries instead of running them to exhaustion. It does not interrupt an
+// attempt already in flight. It's a thin wrapper over
+// RunTransactionWithOptions for callers that don't need ShouldRetry or
+// OnRetry.
+func (d *Database) RunTransactionWithContext(ctx context.Context, fn func(*Transaction) error) error {
+	return d.RunTransactionWithOptions(RunTransactionOptions{Context: ctx}, fn)
+}
+
+// QueryFirstWithContext is the context-aware variant of QueryFirst.
+func (c *Collection) QueryFirstWithContext(ctx context.Context, filter string, sortField string, sortAsc bool, result interface{}) (bool, error) {
+	ctx, cancel := c.contextForCall(ctx)
+	defer cancel()
+
+	var found bool
+	err := runWithContext(ctx, func() error {
+		var err error
+		found, err = c.QueryFirst(filter, sortField, sortAsc, result)
+		return err
+	})
+	return found, err
+}
+
+// BulkWriteWithContext is the context-aware variant of BulkWrite.
+func (c *Collection) BulkWriteWithContext(ctx context.Context, ops []BulkOp, opts BulkOptions) (*BulkWriteResult, error) {
+	ctx, cancel := c.contextForCall(ctx)
+	defer cancel()
+
+	var result *BulkWriteResult
+	err := runWithContext(ctx, func() error {
+		var err error
+		result, err = c.BulkWrite(ops, opts)
+		return err
+	})
+	return result, err
+}
+
+// AggregateWithContext is the context-aware variant of Aggregate.
+func (c *Collection) AggregateWithContext(ctx context.Context, pipeline []Stage, result interface{}) error {
+	ctx, cancel := c.contextForCall(ctx)
+	defer cancel()
+
+	return runWithContext(ctx, func() error {
+		return c.Aggregate(pipeline, result)
+	})
+}
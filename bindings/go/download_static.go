@@ -1,121 +1,200 @@
+//go:build ignore
 // +build ignore
 
 package main
 
 import (
+	_ "embed"
+	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+
+	"github.com/sohzm/jasonisnthappy/bindings/go/internal/installutil"
 )
 
 const releaseURL = "https://github.com/sohzm/jasonisnthappy/releases/latest/download"
 
+//go:embed libraries.sum
+var librariesSumData string
+
+// allPlatforms lists every goos/goarch pair this installer knows how to
+// fetch a static archive for, used by --all and --targets.
+var allPlatforms = []struct{ goos, arch string }{
+	{"darwin", "arm64"},
+	{"darwin", "amd64"},
+	{"linux", "arm64"},
+	{"linux", "amd64"},
+	{"windows", "amd64"},
+}
+
 func main() {
-	platform := getPlatformInfo()
-	if platform == nil {
-		if runtime.GOOS == "windows" && runtime.GOARCH == "arm64" {
-			fmt.Fprintf(os.Stderr, "❌ Windows ARM64 is not currently supported\n")
+	insecure := flag.Bool("insecure", false, "skip checksum verification of the downloaded library")
+	osFlag := flag.String("os", "", "target GOOS to install for (defaults to $GOOS, then the host OS)")
+	archFlag := flag.String("arch", "", "target GOARCH to install for (defaults to $GOARCH, then the host arch)")
+	all := flag.Bool("all", false, "download the library for every supported GOOS/GOARCH pair")
+	targetsFlag := flag.String("targets", "", "comma-separated GOOS/GOARCH pairs to install, e.g. darwin/arm64,linux/amd64")
+	proxyFlag := flag.String("proxy", "", "comma-separated mirror list to try before the canonical release URL (see $JASONISNTHAPPY_PROXY)")
+	flag.Parse()
+
+	var targets []struct{ goos, arch string }
+	switch {
+	case *targetsFlag != "":
+		var err error
+		targets, err = parseTargets(*targetsFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Fprintf(os.Stderr, "❌ Unsupported platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	case *all:
+		targets = allPlatforms
+	default:
+		goos := *osFlag
+		if goos == "" {
+			goos = envDefault("GOOS", runtime.GOOS)
+		}
+		goarch := *archFlag
+		if goarch == "" {
+			goarch = envDefault("GOARCH", runtime.GOARCH)
+		}
+		targets = []struct{ goos, arch string }{{goos, goarch}}
+	}
+
+	var sums map[string]string
+	if !*insecure {
+		var err error
+		sums, err = installutil.ParseSums(librariesSumData)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to parse libraries.sum: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	proxy := proxyList(*proxyFlag)
+
+	failed := false
+	for _, t := range targets {
+		if err := installOne(t.goos, t.arch, *insecure, sums, proxy); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s/%s: %v\n", t.goos, t.arch, err)
+			failed = true
+		}
+	}
+	if failed {
 		os.Exit(1)
 	}
+}
+
+func installOne(goos, goarch string, insecure bool, sums map[string]string, proxy string) error {
+	platform := getPlatformInfo(goos, goarch)
+	if platform == nil {
+		if goos == "windows" && goarch == "arm64" {
+			return fmt.Errorf("Windows ARM64 is not currently supported")
+		}
+		return fmt.Errorf("unsupported platform: %s/%s", goos, goarch)
+	}
 
-	// Determine library directory
 	libDir := filepath.Join("lib", platform.dir)
 	libPath := filepath.Join(libDir, platform.dest)
 
-	// Check if library already exists
 	if _, err := os.Stat(libPath); err == nil {
 		fmt.Fprintf(os.Stderr, "✓ Static library already exists at %s\n", libPath)
-		return
-	}
-
-	// Create lib directory
-	if err := os.MkdirAll(libDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Failed to create lib directory: %v\n", err)
-		os.Exit(1)
+		return nil
 	}
 
-	url := fmt.Sprintf("%s/%s", releaseURL, platform.file)
-	fmt.Fprintf(os.Stderr, "📥 Downloading static library for %s/%s...\n", runtime.GOOS, runtime.GOARCH)
-	fmt.Fprintf(os.Stderr, "   URL: %s\n", url)
+	directURL := fmt.Sprintf("%s/%s", releaseURL, platform.file)
+	sources := installutil.ResolveSources(proxy, platform.dir, platform.file, directURL)
+	fmt.Fprintf(os.Stderr, "📥 Downloading static library for %s/%s...\n", goos, goarch)
+	fmt.Fprintf(os.Stderr, "   URL: %s\n", directURL)
 
-	// Download
-	resp, err := http.Get(url)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Failed to download: %v\n", err)
-		os.Exit(1)
+	if err := installutil.Install(sources, libPath, installutil.Options{
+		Insecure: insecure,
+		Sums:     sums,
+		SumKey:   platform.file,
+	}); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		fmt.Fprintf(os.Stderr, "❌ Download failed: HTTP %d\n", resp.StatusCode)
-		fmt.Fprintf(os.Stderr, "   Make sure the release exists at: %s\n", url)
-		os.Exit(1)
+	if insecure {
+		fmt.Fprintf(os.Stderr, "⚠ --insecure set, skipped checksum verification\n")
+	} else {
+		fmt.Fprintf(os.Stderr, "✓ Checksum verified\n")
 	}
+	fmt.Fprintf(os.Stderr, "✓ Successfully downloaded to %s\n", libPath)
+	fmt.Fprintf(os.Stderr, "✓ You can now run 'go build' to create a static binary\n")
+	return nil
+}
 
-	out, err := os.Create(libPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Failed to create file: %v\n", err)
-		os.Exit(1)
+// envDefault returns the GOOS/GOARCH env var value when set, falling
+// back to the host's own runtime.GOOS/runtime.GOARCH otherwise, the same
+// precedence `go build` itself uses for cross-compilation.
+func envDefault(envVar, hostValue string) string {
+	if v := os.Getenv(envVar); v != "" {
+		return v
 	}
-	defer out.Close()
+	return hostValue
+}
 
-	if _, err := io.Copy(out, resp.Body); err != nil {
-		os.Remove(libPath)
-		fmt.Fprintf(os.Stderr, "❌ Failed to save file: %v\n", err)
-		os.Exit(1)
+// proxyList returns the configured JASONISNTHAPPY_PROXY mirror list,
+// falling back to proxyFlag when the env var isn't set.
+func proxyList(proxyFlag string) string {
+	if v := os.Getenv("JASONISNTHAPPY_PROXY"); v != "" {
+		return v
 	}
+	return proxyFlag
+}
 
-	fmt.Fprintf(os.Stderr, "✓ Successfully downloaded to %s\n", libPath)
-	fmt.Fprintf(os.Stderr, "✓ You can now run 'go build' to create a static binary\n")
+// parseTargets parses a "--targets" value like
+// "darwin/arm64,linux/amd64,windows/amd64" into goos/goarch pairs.
+func parseTargets(targets string) ([]struct{ goos, arch string }, error) {
+	var out []struct{ goos, arch string }
+	for _, t := range strings.Split(targets, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		parts := strings.SplitN(t, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid target %q, expected GOOS/GOARCH", t)
+		}
+		out = append(out, struct{ goos, arch string }{parts[0], parts[1]})
+	}
+	return out, nil
 }
 
 type platformInfo struct {
+	goos string
+	arch string
 	dir  string
 	file string
 	dest string
 }
 
-func getPlatformInfo() *platformInfo {
-	switch runtime.GOOS {
+func getPlatformInfo(goos, goarch string) *platformInfo {
+	switch goos {
 	case "darwin":
-		if runtime.GOARCH == "arm64" {
-			return &platformInfo{
-				dir:  "darwin-arm64",
-				file: "darwin-arm64-static.a",
-				dest: "libjasonisnthappy.a",
-			}
-		}
 		return &platformInfo{
-			dir:  "darwin-amd64",
-			file: "darwin-amd64-static.a",
+			goos: goos, arch: goarch,
+			dir:  "darwin-" + goarch,
+			file: fmt.Sprintf("darwin-%s-static.a", goarch),
 			dest: "libjasonisnthappy.a",
 		}
 	case "linux":
-		if runtime.GOARCH == "arm64" {
-			return &platformInfo{
-				dir:  "linux-arm64",
-				file: "linux-arm64-static.a",
-				dest: "libjasonisnthappy.a",
-			}
-		}
 		return &platformInfo{
-			dir:  "linux-amd64",
-			file: "linux-amd64-static.a",
+			goos: goos, arch: goarch,
+			dir:  "linux-" + goarch,
+			file: fmt.Sprintf("linux-%s-static.a", goarch),
 			dest: "libjasonisnthappy.a",
 		}
 	case "windows":
-		if runtime.GOARCH == "arm64" {
+		if goarch == "arm64" {
 			return nil
 		}
 		return &platformInfo{
-			dir:  "windows-amd64",
-			file: "windows-amd64-static.lib",
+			goos: goos, arch: goarch,
+			dir:  "windows-" + goarch,
+			file: fmt.Sprintf("windows-%s-static.lib", goarch),
 			dest: "jasonisnthappy.lib",
 		}
 	}
@@ -0,0 +1,203 @@
+package jasonisnthappy
+
+import (
+	"fmt"
+	"io"
+)
+
+// GridFS stores files too large for a single document by chunking them
+// into a companion collection, mirroring mgo's gridfs.go API. It's a
+// thin, name-addressable layer over Bucket, which already implements the
+// chunked layout and the atomic upload transaction.
+type GridFS struct {
+	bucket *Bucket
+}
+
+// GridFS returns a GridFS rooted at "<prefix>.files"/"<prefix>.chunks" —
+// the same collection layout Database.Bucket uses with prefix as its
+// name.
+func (d *Database) GridFS(prefix string) (*GridFS, error) {
+	bucket, err := d.BucketWithOptions(prefix, BucketOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &GridFS{bucket: bucket}, nil
+}
+
+// GridFile is a single file opened through a GridFS, either for writing
+// (from Create) or for reading (from Open/OpenId). Only the methods for
+// the mode it was opened in are valid.
+type GridFile struct {
+	upload   *UploadStream
+	download *DownloadStream
+}
+
+var _ io.ReadWriteSeeker = (*GridFile)(nil)
+
+// Create begins writing a new file named name. Write its bytes to the
+// returned GridFile and call Close to commit them.
+func (g *GridFS) Create(name string) (*GridFile, error) {
+	u, err := g.bucket.OpenUploadStream(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &GridFile{upload: u}, nil
+}
+
+// Open opens the most recently uploaded file named name for reading.
+func (g *GridFS) Open(name string) (*GridFile, error) {
+	id, err := g.findIDByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return g.OpenId(id)
+}
+
+// OpenId opens the file stored under id for reading.
+func (g *GridFS) OpenId(id string) (*GridFile, error) {
+	d, err := g.bucket.OpenDownloadStream(id)
+	if err != nil {
+		return nil, err
+	}
+	return &GridFile{download: d}, nil
+}
+
+// Remove deletes the most recently uploaded file named name, along with
+// its chunks.
+func (g *GridFS) Remove(name string) error {
+	id, err := g.findIDByName(name)
+	if err != nil {
+		return err
+	}
+	return g.bucket.Delete(id)
+}
+
+// Find returns the file metadata matching filter (the same query-object
+// syntax Collection.Find accepts).
+func (g *GridFS) Find(filter string) ([]FileInfo, error) {
+	return g.bucket.Find(filter)
+}
+
+func (g *GridFS) findIDByName(name string) (string, error) {
+	files, err := g.bucket.Find(fmt.Sprintf(`{"filename": %q}`, name))
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", &Error{Code: -1, Message: fmt.Sprintf("gridfs: no file named %q", name)}
+	}
+	latest := files[0]
+	for _, f := range files[1:] {
+		if f.UploadDate.After(latest.UploadDate) {
+			latest = f
+		}
+	}
+	return latest.ID, nil
+}
+
+// Write appends p to the file being created. Only valid on a GridFile
+// returned by Create.
+func (g *GridFile) Write(p []byte) (int, error) {
+	if g.upload == nil {
+		return 0, &Error{Code: -1, Message: "GridFile is not open for writing"}
+	}
+	return g.upload.Write(p)
+}
+
+// Read reads the next bytes of the file being opened. Only valid on a
+// GridFile returned by Open/OpenId.
+func (g *GridFile) Read(p []byte) (int, error) {
+	if g.download == nil {
+		return 0, &Error{Code: -1, Message: "GridFile is not open for reading"}
+	}
+	return g.download.Read(p)
+}
+
+// Seek repositions a read-mode GridFile. Seek reopens the download, which
+// fetches the file's chunks again, and discards bytes up to the new
+// offset, so every seek rereads from the first chunk. Only valid on a
+// GridFile returned by Open/OpenId.
+func (g *GridFile) Seek(offset int64, whence int) (int64, error) {
+	if g.download == nil {
+		return 0, &Error{Code: -1, Message: "GridFile is not open for reading"}
+	}
+
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = g.download.pos + offset
+	case io.SeekEnd:
+		target = g.download.file.Length + offset
+	default:
+		return 0, &Error{Code: -1, Message: "GridFile.Seek: invalid whence"}
+	}
+
+	fresh, err := g.download.bucket.OpenDownloadStream(g.download.file.ID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.CopyN(io.Discard, fresh, target); err != nil && err != io.EOF {
+		fresh.Close()
+		return 0, err
+	}
+
+	g.download.Close()
+	fresh.pos = target
+	g.download = fresh
+	return target, nil
+}
+
+// Close commits a write-mode GridFile or releases a read-mode GridFile's
+// buffered chunks.
+func (g *GridFile) Close() error {
+	if g.upload != nil {
+		return g.upload.Close()
+	}
+	if g.download != nil {
+		return g.download.Close()
+	}
+	return nil
+}
+
+// SetContentType records the file's MIME type. Only valid before Close on
+// a GridFile returned by Create.
+func (g *GridFile) SetContentType(contentType string) {
+	if g.upload != nil {
+		g.upload.SetContentType(contentType)
+	}
+}
+
+// SetMeta replaces the file's metadata. Only valid before Close on a
+// GridFile returned by Create.
+func (g *GridFile) SetMeta(meta map[string]interface{}) {
+	if g.upload != nil {
+		g.upload.SetMetadata(meta)
+	}
+}
+
+// MD5 returns the file's MD5 checksum: immediately on a read-mode
+// GridFile, or only after Close on a write-mode one.
+func (g *GridFile) MD5() string {
+	if g.download != nil {
+		return g.download.file.MD5
+	}
+	if g.upload != nil {
+		return g.upload.md5Hex
+	}
+	return ""
+}
+
+// Size returns the file's length in bytes: the final length on a
+// read-mode GridFile, or the number of bytes written so far (not yet
+// final) on a write-mode one.
+func (g *GridFile) Size() int64 {
+	if g.download != nil {
+		return g.download.file.Length
+	}
+	if g.upload != nil {
+		return g.upload.length
+	}
+	return 0
+}
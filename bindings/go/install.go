@@ -1,3 +1,4 @@
+//go:build ignore
 // +build ignore
 
 // Install command for downloading jasonisnthappy native libraries
@@ -5,134 +6,203 @@
 package main
 
 import (
+	_ "embed"
+	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+
+	"github.com/sohzm/jasonisnthappy/bindings/go/internal/installutil"
 )
 
 const releaseURL = "https://github.com/sohzm/jasonisnthappy/releases/latest/download"
 
+//go:embed libraries.sum
+var librariesSumData string
+
 type platformInfo struct {
+	goos string
+	arch string
 	dir  string
 	file string
 	dest string
 }
 
-func getPlatformInfo() *platformInfo {
-	switch runtime.GOOS {
+// allPlatforms lists every goos/goarch pair install.go knows how to fetch
+// a dynamic library for, used by --all and --targets.
+var allPlatforms = []struct{ goos, arch string }{
+	{"darwin", "arm64"},
+	{"darwin", "amd64"},
+	{"linux", "arm64"},
+	{"linux", "amd64"},
+	{"windows", "amd64"},
+}
+
+func getPlatformInfo(goos, goarch string) *platformInfo {
+	switch goos {
 	case "darwin":
-		if runtime.GOARCH == "arm64" {
-			return &platformInfo{
-				dir:  "darwin-arm64",
-				file: "darwin-arm64-dynamic.dylib",
-				dest: "libjasonisnthappy.dylib",
-			}
-		}
 		return &platformInfo{
-			dir:  "darwin-amd64",
-			file: "darwin-amd64-dynamic.dylib",
+			goos: goos, arch: goarch,
+			dir:  "darwin-" + goarch,
+			file: fmt.Sprintf("darwin-%s-dynamic.dylib", goarch),
 			dest: "libjasonisnthappy.dylib",
 		}
 	case "linux":
-		if runtime.GOARCH == "arm64" {
-			return &platformInfo{
-				dir:  "linux-arm64",
-				file: "linux-arm64-dynamic.so",
-				dest: "libjasonisnthappy.so",
-			}
-		}
 		return &platformInfo{
-			dir:  "linux-amd64",
-			file: "linux-amd64-dynamic.so",
+			goos: goos, arch: goarch,
+			dir:  "linux-" + goarch,
+			file: fmt.Sprintf("linux-%s-dynamic.so", goarch),
 			dest: "libjasonisnthappy.so",
 		}
 	case "windows":
-		if runtime.GOARCH == "arm64" {
+		if goarch == "arm64" {
 			fmt.Fprintf(os.Stderr, "Windows ARM64 is not currently supported.\n")
 			return nil
 		}
 		return &platformInfo{
-			dir:  "windows-amd64",
-			file: "windows-amd64-dynamic.dll",
+			goos: goos, arch: goarch,
+			dir:  "windows-" + goarch,
+			file: fmt.Sprintf("windows-%s-dynamic.dll", goarch),
 			dest: "jasonisnthappy.dll",
 		}
 	}
 	return nil
 }
 
-func main() {
-	platform := getPlatformInfo()
-	if platform == nil {
-		fmt.Fprintf(os.Stderr, "Unsupported platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
-		os.Exit(1)
+// envDefault returns the GOOS/GOARCH env var value when set, falling
+// back to the host's own runtime.GOOS/runtime.GOARCH otherwise, the same
+// precedence `go build` itself uses for cross-compilation.
+func envDefault(envVar, hostValue string) string {
+	if v := os.Getenv(envVar); v != "" {
+		return v
 	}
+	return hostValue
+}
 
-	// Install to lib directory in Go module
-	// Find the module directory
-	libDir := filepath.Join("lib", platform.dir)
-	if err := os.MkdirAll(libDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create lib directory: %v\n", err)
-		os.Exit(1)
+// proxyList returns the configured JASONISNTHAPPY_PROXY mirror list,
+// falling back to proxyFlag when the env var isn't set.
+func proxyList(proxyFlag string) string {
+	if v := os.Getenv("JASONISNTHAPPY_PROXY"); v != "" {
+		return v
 	}
+	return proxyFlag
+}
 
-	destPath := filepath.Join(libDir, platform.dest)
+// parseTargets parses a "--targets" value like
+// "darwin/arm64,linux/amd64,windows/amd64" into goos/goarch pairs.
+func parseTargets(targets string) ([]struct{ goos, arch string }, error) {
+	var out []struct{ goos, arch string }
+	for _, t := range strings.Split(targets, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		parts := strings.SplitN(t, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid target %q, expected GOOS/GOARCH", t)
+		}
+		out = append(out, struct{ goos, arch string }{parts[0], parts[1]})
+	}
+	return out, nil
+}
 
-	// Skip if already exists
-	if _, err := os.Stat(destPath); err == nil {
-		fmt.Printf("✓ Library already exists at %s\n", destPath)
-		return
+func main() {
+	insecure := flag.Bool("insecure", false, "skip checksum verification of the downloaded library")
+	osFlag := flag.String("os", "", "target GOOS to install for (defaults to $GOOS, then the host OS)")
+	archFlag := flag.String("arch", "", "target GOARCH to install for (defaults to $GOARCH, then the host arch)")
+	all := flag.Bool("all", false, "download the library for every supported GOOS/GOARCH pair")
+	targetsFlag := flag.String("targets", "", "comma-separated GOOS/GOARCH pairs to install, e.g. darwin/arm64,linux/amd64")
+	proxyFlag := flag.String("proxy", "", "comma-separated mirror list to try before the canonical release URL (see $JASONISNTHAPPY_PROXY)")
+	flag.Parse()
+
+	var targets []struct{ goos, arch string }
+	switch {
+	case *targetsFlag != "":
+		var err error
+		targets, err = parseTargets(*targetsFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	case *all:
+		targets = allPlatforms
+	default:
+		goos := *osFlag
+		if goos == "" {
+			goos = envDefault("GOOS", runtime.GOOS)
+		}
+		goarch := *archFlag
+		if goarch == "" {
+			goarch = envDefault("GOARCH", runtime.GOARCH)
+		}
+		targets = []struct{ goos, arch string }{{goos, goarch}}
 	}
 
-	url := fmt.Sprintf("%s/%s", releaseURL, platform.file)
-	fmt.Printf("Downloading jasonisnthappy native library for %s-%s...\n", runtime.GOOS, runtime.GOARCH)
-	fmt.Printf("URL: %s\n", url)
+	var sums map[string]string
+	if !*insecure {
+		var err error
+		sums, err = installutil.ParseSums(librariesSumData)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to parse libraries.sum: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	// Download
-	resp, err := http.Get(url)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to download: %v\n", err)
+	proxy := proxyList(*proxyFlag)
+
+	failed := false
+	for _, t := range targets {
+		if err := installOne(t.goos, t.arch, *insecure, sums, proxy); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to install %s/%s: %v\n", t.goos, t.arch, err)
+			failed = true
+		}
+	}
+	if failed {
 		os.Exit(1)
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != 200 {
-		fmt.Fprintf(os.Stderr, "Download failed: HTTP %d\n", resp.StatusCode)
-		os.Exit(1)
+func installOne(goos, goarch string, insecure bool, sums map[string]string, proxy string) error {
+	platform := getPlatformInfo(goos, goarch)
+	if platform == nil {
+		return fmt.Errorf("unsupported platform: %s/%s", goos, goarch)
 	}
 
-	out, err := os.Create(destPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to create file: %v\n", err)
-		os.Exit(1)
+	libDir := filepath.Join("lib", platform.dir)
+	destPath := filepath.Join(libDir, platform.dest)
+
+	if _, err := os.Stat(destPath); err == nil {
+		fmt.Printf("✓ Library already exists at %s\n", destPath)
+		return nil
 	}
-	defer out.Close()
-
-	// Copy with progress
-	total := resp.ContentLength
-	var downloaded int64
-	buf := make([]byte, 32*1024)
-	for {
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			out.Write(buf[:n])
-			downloaded += int64(n)
+
+	directURL := fmt.Sprintf("%s/%s", releaseURL, platform.file)
+	sources := installutil.ResolveSources(proxy, platform.dir, platform.file, directURL)
+	fmt.Printf("Downloading jasonisnthappy native library for %s-%s...\n", goos, goarch)
+	fmt.Printf("URL: %s\n", directURL)
+
+	err := installutil.Install(sources, destPath, installutil.Options{
+		Insecure: insecure,
+		Sums:     sums,
+		SumKey:   platform.file,
+		Progress: func(downloaded, total int64) {
 			if total > 0 {
-				percent := float64(downloaded) / float64(total) * 100
-				fmt.Printf("\rProgress: %.1f%%", percent)
+				fmt.Printf("\rProgress: %.1f%%", float64(downloaded)/float64(total)*100)
 			}
-		}
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			os.Remove(destPath)
-			fmt.Fprintf(os.Stderr, "\nFailed to download: %v\n", err)
-			os.Exit(1)
-		}
+		},
+	})
+	fmt.Println()
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("\n✓ Successfully downloaded to %s\n", destPath)
+	if insecure {
+		fmt.Fprintf(os.Stderr, "⚠ --insecure set, skipped checksum verification\n")
+	} else {
+		fmt.Println("✓ Checksum verified")
+	}
+	fmt.Printf("✓ Successfully downloaded to %s\n", destPath)
+	return nil
 }
@@ -0,0 +1,423 @@
+// Package installutil holds the download, checksum-verification, and
+// concurrency-safe install logic shared by install.go, download_static.go,
+// and cmd/install/main.go, so the three installers don't each carry their
+// own copy of the same race-prone download-then-rename sequence.
+package installutil
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ParseSums parses a libraries.sum manifest's contents into a map of
+// artifact name to its "h1:<base64-sha256>" checksum. Blank lines and
+// lines starting with "#" are ignored.
+func ParseSums(data string) (map[string]string, error) {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed libraries.sum line: %q", line)
+		}
+		sums[fields[0]] = fields[1]
+	}
+	return sums, scanner.Err()
+}
+
+// Sha256Sum streams path through SHA-256 and returns it in libraries.sum's
+// "h1:<base64>" form.
+func Sha256Sum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// Options configures Install.
+type Options struct {
+	// Insecure skips checksum verification when true.
+	Insecure bool
+	// Sums is a libraries.sum manifest, keyed by artifact name. Required
+	// unless Insecure is set.
+	Sums map[string]string
+	// SumKey is the artifact name to look up in Sums.
+	SumKey string
+	// Progress, if set, is called as the download proceeds. total is -1
+	// when the server didn't report a Content-Length.
+	Progress func(downloaded, total int64)
+}
+
+// Source is one candidate location to fetch an artifact from, as
+// produced by ResolveSources.
+type Source struct {
+	// URL is an "https://" or "file://" location.
+	URL string
+	// Direct marks a Source as the canonical release URL, resolved from
+	// an explicit "direct" entry in a proxy list. A Direct source is
+	// tried even after a preceding source's fatal (non-"not found")
+	// error, since it's the one location that was never supposed to
+	// depend on a working mirror.
+	Direct bool
+}
+
+// ResolveSources expands a comma-separated proxy list - the value of
+// JASONISNTHAPPY_PROXY or -proxy - into an ordered list of Sources to
+// try for one artifact. Each entry is either "direct" (the canonical
+// directURL), "off" (stop considering any further entries), or a
+// template URL with "{platform}" and "{filename}" substituted in, e.g.
+// "https://mirror.example.com/{platform}/{filename}" or
+// "file:///srv/mirror/{filename}". An empty proxyList resolves to just
+// directURL, so callers don't need to special-case the unset case.
+func ResolveSources(proxyList, platform, filename, directURL string) []Source {
+	if proxyList == "" {
+		return []Source{{URL: directURL, Direct: true}}
+	}
+	repl := strings.NewReplacer("{platform}", platform, "{filename}", filename)
+	var sources []Source
+	for _, entry := range strings.Split(proxyList, ",") {
+		entry = strings.TrimSpace(entry)
+		switch entry {
+		case "":
+			continue
+		case "off":
+			return sources
+		case "direct":
+			sources = append(sources, Source{URL: directURL, Direct: true})
+		default:
+			sources = append(sources, Source{URL: repl.Replace(entry)})
+		}
+	}
+	return sources
+}
+
+// Install downloads the first of sources that succeeds into destPath,
+// falling through to the next source when one reports the artifact
+// doesn't exist there (HTTP 404, or a missing file:// path) and, for any
+// other error, only as far as the next Direct source - a mirror that's
+// merely unreachable shouldn't be silently skipped in favor of a mirror
+// further down the list. It acquires an exclusive lock on destPath's
+// directory first, so two `go build` invocations racing in a CI matrix
+// can't both see the file missing and truncate each other's write; the
+// loser simply waits for the lock and then, on seeing destPath already
+// exists, returns without re-downloading. The artifact is written to a
+// "<destPath>.partial" file that download resumes from on a retry,
+// checksum-verified against opts.Sums (unless opts.Insecure), and only
+// then renamed atomically over destPath. On any failure after the
+// download the partial file is left in place only if the failure was
+// transient and worth resuming; otherwise it's removed and destPath is
+// left untouched.
+func Install(sources []Source, destPath string, opts Options) error {
+	dir := filepath.Dir(destPath)
+	l, err := acquireLock(dir)
+	if err != nil {
+		return fmt.Errorf("acquiring install lock: %w", err)
+	}
+	defer l.Release()
+
+	if _, err := os.Stat(destPath); err == nil {
+		return nil
+	}
+
+	partialPath := destPath + ".partial"
+	if err := fetchFromSources(sources, partialPath, opts.Progress); err != nil {
+		return err
+	}
+
+	if opts.Insecure {
+		return finish(partialPath, destPath)
+	}
+
+	want, ok := opts.Sums[opts.SumKey]
+	if !ok {
+		os.Remove(partialPath)
+		return fmt.Errorf("no checksum recorded for %s in libraries.sum", opts.SumKey)
+	}
+	got, err := Sha256Sum(partialPath)
+	if err != nil {
+		os.Remove(partialPath)
+		return fmt.Errorf("checksumming downloaded file: %w", err)
+	}
+	if got != want {
+		os.Remove(partialPath)
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", opts.SumKey, got, want)
+	}
+
+	return finish(partialPath, destPath)
+}
+
+func finish(partialPath, destPath string) error {
+	if err := os.Rename(partialPath, destPath); err != nil {
+		os.Remove(partialPath)
+		return fmt.Errorf("installing downloaded file: %w", err)
+	}
+	return nil
+}
+
+// retryBackoff is the delay before each retry of a transient download
+// failure: 1s, then 4s, then 16s.
+var retryBackoff = []time.Duration{1 * time.Second, 4 * time.Second, 16 * time.Second}
+
+// transientErr marks a download failure as worth retrying: a 5xx
+// response, or an error reading the response body (which covers
+// io.ErrUnexpectedEOF, a connection reset mid-download, and similar).
+type transientErr struct{ err error }
+
+func (e transientErr) Error() string { return e.err.Error() }
+func (e transientErr) Unwrap() error { return e.err }
+
+func isTransient(err error) bool {
+	var t transientErr
+	return errors.As(err, &t)
+}
+
+// notFoundErr marks a source as not having the artifact at all (HTTP
+// 404, or a missing file:// path), as opposed to being unreachable or
+// erroring - the distinction fetchFromSources uses to decide whether to
+// try the next source unconditionally or only if it's a Direct one.
+type notFoundErr struct{ err error }
+
+func (e notFoundErr) Error() string { return e.err.Error() }
+func (e notFoundErr) Unwrap() error { return e.err }
+
+func isNotFound(err error) bool {
+	var nf notFoundErr
+	return errors.As(err, &nf)
+}
+
+// fetchFromSources tries each source in order, writing into partialPath.
+// A "not found" result always falls through to the next source; any
+// other error only falls through if the next source is Direct.
+func fetchFromSources(sources []Source, partialPath string, progress func(downloaded, total int64)) error {
+	if len(sources) == 0 {
+		return fmt.Errorf("no download sources configured")
+	}
+	var lastErr error
+	for i, src := range sources {
+		err := fetch(src.URL, partialPath, progress)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if isNotFound(err) {
+			continue
+		}
+		if i+1 < len(sources) && sources[i+1].Direct {
+			fmt.Fprintf(os.Stderr, "source %s failed (%v), falling back to direct source\n", src.URL, err)
+			continue
+		}
+		return err
+	}
+	return fmt.Errorf("all download sources failed: %w", lastErr)
+}
+
+// fetch downloads rawURL into partialPath, dispatching on scheme: a
+// "file://" URL is copied from the local filesystem, anything else goes
+// through the retrying HTTP downloader.
+func fetch(rawURL, partialPath string, progress func(downloaded, total int64)) error {
+	if u, err := url.Parse(rawURL); err == nil && u.Scheme == "file" {
+		return fetchFile(u.Path, partialPath, progress)
+	}
+	return download(rawURL, partialPath, progress)
+}
+
+// fetchFile copies path into partialPath wholesale, for a "file://"
+// proxy entry. Local mirrors don't need resumability or retries - a
+// failed read is either the file being genuinely absent (treated like a
+// 404, so the next source is tried) or a real filesystem error.
+func fetchFile(path, partialPath string, progress func(downloaded, total int64)) error {
+	in, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return notFoundErr{err}
+		}
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(partialPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open partial file: %w", err)
+	}
+	defer out.Close()
+
+	n, err := io.Copy(out, in)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s: %w", path, err)
+	}
+	if progress != nil {
+		progress(n, n)
+	}
+	return out.Sync()
+}
+
+// downloadMeta records the validators of an in-progress partial download,
+// so a later attempt can send an If-Range header and only resume if the
+// artifact on the server hasn't changed since.
+type downloadMeta struct {
+	ETag         string
+	LastModified string
+}
+
+func readMeta(path string) *downloadMeta {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lines := strings.SplitN(string(data), "\n", 2)
+	m := &downloadMeta{ETag: lines[0]}
+	if len(lines) > 1 {
+		m.LastModified = lines[1]
+	}
+	return m
+}
+
+func writeMeta(path string, m downloadMeta) error {
+	return os.WriteFile(path, []byte(m.ETag+"\n"+m.LastModified), 0644)
+}
+
+// download fetches url into partialPath, resuming from partialPath's
+// existing size via an HTTP Range request when possible, and retrying
+// transient failures with exponential backoff (1s, 4s, 16s).
+func download(url, partialPath string, progress func(downloaded, total int64)) error {
+	metaPath := partialPath + ".meta"
+
+	var lastErr error
+	for attempt := 0; attempt <= len(retryBackoff); attempt++ {
+		if attempt > 0 {
+			time.Sleep(retryBackoff[attempt-1])
+		}
+
+		err := downloadOnce(url, partialPath, metaPath, progress)
+		if err == nil {
+			os.Remove(metaPath)
+			return nil
+		}
+		if !isTransient(err) {
+			return err
+		}
+		lastErr = err
+		fmt.Fprintf(os.Stderr, "download attempt %d/%d failed, retrying: %v\n", attempt+1, len(retryBackoff)+1, err)
+	}
+	return fmt.Errorf("download failed after %d attempts: %w", len(retryBackoff)+1, lastErr)
+}
+
+// downloadOnce makes a single download attempt, resuming from
+// partialPath's current size if a previous attempt left one behind.
+func downloadOnce(url, partialPath, metaPath string, progress func(downloaded, total int64)) error {
+	var offset int64
+	var meta *downloadMeta
+	if fi, err := os.Stat(partialPath); err == nil {
+		offset = fi.Size()
+		meta = readMeta(metaPath)
+		if meta == nil {
+			// No validators recorded for the partial file: it may
+			// predate this logic, or the meta write was interrupted.
+			// Restart rather than risk appending to stale bytes.
+			offset = 0
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if meta.ETag != "" {
+			req.Header.Set("If-Range", meta.ETag)
+		} else if meta.LastModified != "" {
+			req.Header.Set("If-Range", meta.LastModified)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return transientErr{fmt.Errorf("failed to download: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	openFlag := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		fmt.Fprintf(os.Stderr, "resuming download from byte %d\n", offset)
+		openFlag |= os.O_APPEND
+	case http.StatusOK:
+		if offset > 0 {
+			fmt.Fprintf(os.Stderr, "server did not honor resume, restarting download\n")
+		}
+		offset = 0
+		openFlag |= os.O_TRUNC
+		if err := writeMeta(metaPath, downloadMeta{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}); err != nil {
+			return fmt.Errorf("recording download validators: %w", err)
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		os.Remove(partialPath)
+		os.Remove(metaPath)
+		return transientErr{fmt.Errorf("requested range not satisfiable, restarting")}
+	case http.StatusNotFound:
+		return notFoundErr{fmt.Errorf("artifact not found: HTTP 404")}
+	default:
+		if resp.StatusCode >= 500 {
+			return transientErr{fmt.Errorf("download failed: HTTP %d", resp.StatusCode)}
+		}
+		return fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
+	}
+
+	out, err := os.OpenFile(partialPath, openFlag, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open partial file: %w", err)
+	}
+	defer out.Close()
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	downloaded := offset
+	buf := make([]byte, 32*1024)
+	for {
+		n, rerr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := out.Write(buf[:n]); werr != nil {
+				return fmt.Errorf("failed to save file: %w", werr)
+			}
+			downloaded += int64(n)
+			if progress != nil {
+				progress(downloaded, total)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			// Leave the partial file in place so the next attempt can
+			// resume instead of starting over.
+			return transientErr{fmt.Errorf("failed to download: %w", rerr)}
+		}
+	}
+
+	return out.Sync()
+}
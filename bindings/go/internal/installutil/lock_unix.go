@@ -0,0 +1,35 @@
+//go:build !windows
+
+package installutil
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// fileLock is an advisory exclusive lock on <dir>/.install.lock, held for
+// the duration of a single Install call.
+type fileLock struct {
+	f *os.File
+}
+
+func acquireLock(dir string) (*fileLock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, ".install.lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileLock{f: f}, nil
+}
+
+func (l *fileLock) Release() error {
+	defer l.f.Close()
+	return syscall.Flock(int(l.f.Fd()), syscall.LOCK_UN)
+}
@@ -21,8 +21,18 @@ extern void goWatchCallbackBridge(char *collection, char *operation, char *doc_i
 */
 import "C"
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+	"unicode"
 	"unsafe"
 )
 
@@ -36,6 +46,74 @@ func (e *Error) Error() string {
 	return e.Message
 }
 
+// ErrCodeConflict is the Error.Code the core returns when a transaction
+// can't commit because another transaction modified the same data first.
+// RunTransaction matches on this rather than Error.Message so a future
+// wording change in the core can't silently stop retries from firing.
+const ErrCodeConflict = 2
+
+// ErrDuplicateKey is returned by Insert/Update/Upsert when the write would
+// violate a unique index, mirroring the ergonomics mgo/tiedot users expect.
+// Callers should test for it with IsDup rather than comparing error strings.
+//
+// The core reports every error through the same CError.code (-1), so this
+// is detected from Message rather than a dedicated code. Index is the
+// violated index's name; Value is the Rust Debug-formatted field value(s)
+// exactly as the core embeds them in the message (e.g. `String("a")` for a
+// single-field index, `[String("a"), Number(1)]` for a compound one) —
+// there's no separately typed value to parse it into.
+type ErrDuplicateKey struct {
+	Index   string
+	Value   string
+	message string
+}
+
+func (e *ErrDuplicateKey) Error() string {
+	return e.message
+}
+
+// IsDup reports whether err is (or wraps) an ErrDuplicateKey.
+func IsDup(err error) bool {
+	var dup *ErrDuplicateKey
+	return errors.As(err, &dup)
+}
+
+// duplicateKeyPattern matches the core's unique-constraint-violation
+// message, e.g.
+// `unique constraint violation on index email_idx: value String("a") already exists`.
+var duplicateKeyPattern = regexp.MustCompile(`^unique constraint violation on index (\S+): (?:value|combination) (.+) already exists$`)
+
+func parseDuplicateKeyError(message string) *ErrDuplicateKey {
+	dup := &ErrDuplicateKey{message: message}
+	if m := duplicateKeyPattern.FindStringSubmatch(message); m != nil {
+		dup.Index, dup.Value = m[1], m[2]
+	}
+	return dup
+}
+
+// Codec marshals and unmarshals documents to and from a wire format. The
+// database itself always stores and exchanges documents as JSON; a Codec is
+// a Go-side convenience for encoding Go values in another format, such as
+// BSONCodec, which encodes to BSON bytes and round-trips time.Time, []byte,
+// and ObjectID without the precision JSON would lose.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	// ContentType identifies the codec's wire format, e.g. for logging or
+	// content negotiation in code built on top of this package.
+	ContentType() string
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) ContentType() string                        { return "application/json" }
+
+// JSONCodec is the identity Codec: it marshals through encoding/json, the
+// same format the database itself uses.
+var JSONCodec Codec = jsonCodec{}
+
 // Watch callback infrastructure
 var (
 	watchCallbacks   = make(map[uintptr]WatchCallback)
@@ -69,6 +147,14 @@ func goWatchCallbackBridge(collection *C.char, operation *C.char, docID *C.char,
 // Database represents a jasonisnthappy database connection
 type Database struct {
 	db *C.CDatabase
+
+	ttlMu      sync.Mutex
+	ttlIndexes []ttlIndexSpec
+	ttlOnce    sync.Once
+	ttlStop    chan struct{}
+
+	timeoutMu      sync.RWMutex
+	defaultTimeout time.Duration
 }
 
 // Transaction represents a database transaction
@@ -84,7 +170,15 @@ type UpsertResult struct {
 	Inserted bool
 }
 
-// DatabaseOptions holds configuration for opening a database
+// DatabaseOptions holds configuration for opening a database.
+//
+// There's no compression knob here, and none is planned: CDatabaseOptions
+// on the FFI side has exactly the fields mirrored below, with no
+// algorithm/flags/threshold fields for per-value compression, and no
+// per-collection options call to carry one either. A prior pass added a
+// Compression field plus Database.SetCollectionOptions against a storage
+// layer that doesn't exist and was reverted; that request is withdrawn
+// rather than reattempted.
 type DatabaseOptions struct {
 	CacheSize               uint   `json:"cache_size"`
 	AutoCheckpointThreshold uint64 `json:"auto_checkpoint_threshold"`
@@ -132,13 +226,13 @@ func OpenWithOptions(path string, opts DatabaseOptions) (*Database, error) {
 	defer C.free(unsafe.Pointer(cPath))
 
 	cOpts := C.CDatabaseOptions{
-		cache_size:               C.uintptr_t(opts.CacheSize),
+		cache_size:                C.uintptr_t(opts.CacheSize),
 		auto_checkpoint_threshold: C.ulonglong(opts.AutoCheckpointThreshold),
-		file_permissions:         C.uint(opts.FilePermissions),
-		read_only:                C.bool(opts.ReadOnly),
-		max_bulk_operations:      C.uintptr_t(opts.MaxBulkOperations),
-		max_document_size:        C.uintptr_t(opts.MaxDocumentSize),
-		max_request_body_size:    C.uintptr_t(opts.MaxRequestBodySize),
+		file_permissions:          C.uint(opts.FilePermissions),
+		read_only:                 C.bool(opts.ReadOnly),
+		max_bulk_operations:       C.uintptr_t(opts.MaxBulkOperations),
+		max_document_size:         C.uintptr_t(opts.MaxDocumentSize),
+		max_request_body_size:     C.uintptr_t(opts.MaxRequestBodySize),
 	}
 
 	var cErr C.CError
@@ -155,13 +249,29 @@ func OpenWithOptions(path string, opts DatabaseOptions) (*Database, error) {
 
 // Close closes the database
 func (d *Database) Close() {
+	d.ttlMu.Lock()
+	if d.ttlStop != nil {
+		close(d.ttlStop)
+		d.ttlStop = nil
+	}
+	d.ttlMu.Unlock()
+
 	if d.db != nil {
 		C.jasonisnthappy_close(d.db)
 		d.db = nil
 	}
 }
 
-// BeginTransaction starts a new transaction
+// BeginTransaction starts a new transaction.
+//
+// There is no Database.Snapshot decoupled from a Transaction: the core
+// has no jasonisnthappy_snapshot_* entry points, only begin/commit/
+// rollback, so a long-running read-only view of the database has to be
+// a Transaction like any other, which blocks writers for its lifetime.
+// A prior pass at this request added a Snapshot type wired to
+// fabricated FFI calls and was reverted; that request is withdrawn
+// rather than reattempted, since nothing in the core gives a read-only
+// handle a consistent view without holding a transaction open.
 func (d *Database) BeginTransaction() (*Transaction, error) {
 	if d.db == nil {
 		return nil, &Error{Code: -1, Message: "Database is closed"}
@@ -369,9 +479,13 @@ func cErrorToGoError(cErr *C.CError) error {
 	if cErr.code == 0 {
 		return nil
 	}
+	message := C.GoString(cErr.message)
+	if strings.HasPrefix(message, "unique constraint violation on index ") {
+		return parseDuplicateKeyError(message)
+	}
 	return &Error{
 		Code:    int(cErr.code),
-		Message: C.GoString(cErr.message),
+		Message: message,
 	}
 }
 
@@ -381,9 +495,9 @@ func cErrorToGoError(cErr *C.CError) error {
 
 // TransactionConfig holds transaction retry configuration
 type TransactionConfig struct {
-	MaxRetries          uint   `json:"max_retries"`
-	RetryBackoffBaseMs  uint64 `json:"retry_backoff_base_ms"`
-	MaxRetryBackoffMs   uint64 `json:"max_retry_backoff_ms"`
+	MaxRetries         uint   `json:"max_retries"`
+	RetryBackoffBaseMs uint64 `json:"retry_backoff_base_ms"`
+	MaxRetryBackoffMs  uint64 `json:"max_retry_backoff_ms"`
 }
 
 // SetTransactionConfig sets the transaction configuration
@@ -393,7 +507,7 @@ func (d *Database) SetTransactionConfig(config TransactionConfig) error {
 	}
 
 	cConfig := C.CTransactionConfig{
-		max_retries:          C.ulong(config.MaxRetries),
+		max_retries:           C.ulong(config.MaxRetries),
 		retry_backoff_base_ms: C.ulonglong(config.RetryBackoffBaseMs),
 		max_retry_backoff_ms:  C.ulonglong(config.MaxRetryBackoffMs),
 	}
@@ -507,6 +621,23 @@ func (d *Database) MaxBulkOperations() (uint, error) {
 	return uint(result), nil
 }
 
+// SetDefaultTimeout sets the deadline applied to QueryFirstWithContext,
+// BulkWriteWithContext and AggregateWithContext calls on this Database's
+// collections when the caller's own context carries no deadline of its own.
+// The other ...WithContext methods do not apply it. Zero, the default,
+// applies no implicit timeout.
+func (d *Database) SetDefaultTimeout(timeout time.Duration) {
+	d.timeoutMu.Lock()
+	d.defaultTimeout = timeout
+	d.timeoutMu.Unlock()
+}
+
+func (d *Database) defaultTimeoutDuration() time.Duration {
+	d.timeoutMu.RLock()
+	defer d.timeoutMu.RUnlock()
+	return d.defaultTimeout
+}
+
 // MaxDocumentSize returns the maximum document size in bytes
 func (d *Database) MaxDocumentSize() (uint, error) {
 	if d.db == nil {
@@ -784,6 +915,119 @@ func (d *Database) DropIndex(collectionName, indexName string) error {
 	return nil
 }
 
+// ============================================================================
+// EnsureIndex / TTL
+// ============================================================================
+
+// IndexSpec describes an index to create via EnsureIndex.
+type IndexSpec struct {
+	// Fields are the indexed fields, in order. A single field produces a
+	// regular index; more than one produces a compound index.
+	Fields []string
+	// Unique enforces a unique constraint; violating writes return ErrDuplicateKey.
+	Unique bool
+	// TTLSeconds, if nonzero, expires documents whose single indexed field
+	// (a unix timestamp) is older than TTLSeconds ago. Only valid with a
+	// single field.
+	TTLSeconds int64
+}
+
+type ttlIndexSpec struct {
+	collection string
+	field      string
+	ttl        time.Duration
+}
+
+// EnsureIndex declares an index on collectionName, creating it if it
+// doesn't already exist. A nonzero spec.TTLSeconds additionally registers
+// the field with a background sweeper that deletes expired documents via
+// the regular delete path, so expirations also fire watch DELETE events.
+func (d *Database) EnsureIndex(collectionName, indexName string, spec IndexSpec) error {
+	if d.db == nil {
+		return &Error{Code: -1, Message: "Database is closed"}
+	}
+	if len(spec.Fields) == 0 {
+		return &Error{Code: -1, Message: "IndexSpec.Fields must not be empty"}
+	}
+
+	if spec.TTLSeconds > 0 {
+		if len(spec.Fields) != 1 {
+			return &Error{Code: -1, Message: "TTL indexes support exactly one field"}
+		}
+		d.registerTTLIndex(collectionName, spec.Fields[0], time.Duration(spec.TTLSeconds)*time.Second)
+	}
+
+	if len(spec.Fields) == 1 {
+		return d.CreateIndex(collectionName, indexName, spec.Fields[0], spec.Unique)
+	}
+	return d.CreateCompoundIndex(collectionName, indexName, spec.Fields, spec.Unique)
+}
+
+const ttlSweepInterval = 30 * time.Second
+
+func (d *Database) registerTTLIndex(collection, field string, ttl time.Duration) {
+	d.ttlMu.Lock()
+	d.ttlIndexes = append(d.ttlIndexes, ttlIndexSpec{collection: collection, field: field, ttl: ttl})
+	if d.ttlStop == nil {
+		d.ttlStop = make(chan struct{})
+	}
+	d.ttlMu.Unlock()
+
+	d.ttlOnce.Do(func() {
+		go d.runTTLSweeper()
+	})
+}
+
+func (d *Database) runTTLSweeper() {
+	ticker := time.NewTicker(ttlSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		d.ttlMu.Lock()
+		stop := d.ttlStop
+		d.ttlMu.Unlock()
+		if stop == nil {
+			return
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.sweepExpiredDocuments()
+		}
+	}
+}
+
+func (d *Database) sweepExpiredDocuments() {
+	d.ttlMu.Lock()
+	specs := append([]ttlIndexSpec(nil), d.ttlIndexes...)
+	d.ttlMu.Unlock()
+
+	for _, spec := range specs {
+		coll, err := d.GetCollection(spec.collection)
+		if err != nil {
+			continue
+		}
+
+		cutoff := time.Now().Add(-spec.ttl).Unix()
+		filter, _ := json.Marshal(map[string]interface{}{
+			spec.field: map[string]interface{}{"$lt": cutoff},
+		})
+
+		var expired []map[string]interface{}
+		if err := coll.Find(string(filter), &expired); err == nil {
+			for _, doc := range expired {
+				if id, ok := doc["_id"].(string); ok {
+					_ = coll.DeleteByID(id)
+				}
+			}
+		}
+
+		coll.Free()
+	}
+}
+
 // ============================================================================
 // Schema Validation
 // ============================================================================
@@ -1022,14 +1266,48 @@ func (d *Database) FrameCount() (uint64, error) {
 // Additional Transaction Operations
 // ============================================================================
 
-// RunTransaction runs a transaction with automatic retries
+// RunTransactionOptions configures RunTransactionWithOptions.
+type RunTransactionOptions struct {
+	// Context, if set, aborts the retry loop early: once it's done, the
+	// loop returns its error instead of starting another attempt or
+	// sleeping out a pending backoff.
+	Context context.Context
+
+	// ShouldRetry decides whether a commit error is worth retrying.
+	// Defaults to matching ErrCodeConflict.
+	ShouldRetry func(err error) bool
+
+	// OnRetry, if set, is called with the attempt number (0-indexed) and
+	// the error that triggered it, after a failed attempt but before the
+	// backoff sleep, for observability.
+	OnRetry func(attempt uint, err error)
+}
+
+// defaultShouldRetry retries only on the core's transaction-conflict code.
+func defaultShouldRetry(err error) bool {
+	cErr, ok := err.(*Error)
+	return ok && cErr.Code == ErrCodeConflict
+}
+
+// RunTransaction runs fn in a transaction, retrying on conflict with
+// exponential backoff up to the database's TransactionConfig.MaxRetries.
 func (d *Database) RunTransaction(fn func(*Transaction) error) error {
+	return d.RunTransactionWithOptions(RunTransactionOptions{}, fn)
+}
+
+// RunTransactionWithOptions is like RunTransaction, but lets the caller
+// supply a Context to abort retries early, a ShouldRetry predicate to
+// widen or narrow which commit errors are retried, and an OnRetry hook.
+func (d *Database) RunTransactionWithOptions(opts RunTransactionOptions, fn func(*Transaction) error) error {
 	if d.db == nil {
 		return &Error{Code: -1, Message: "Database is closed"}
 	}
 
-	// We'll use begin/commit/rollback manually since Go callbacks don't work with CGo easily
-	// This is a simpler approach than the C callback version
+	shouldRetry := opts.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = defaultShouldRetry
+	}
+
 	config, err := d.GetTransactionConfig()
 	if err != nil {
 		config = &TransactionConfig{MaxRetries: 3, RetryBackoffBaseMs: 10, MaxRetryBackoffMs: 1000}
@@ -1037,6 +1315,12 @@ func (d *Database) RunTransaction(fn func(*Transaction) error) error {
 
 	var lastErr error
 	for attempt := uint(0); attempt <= config.MaxRetries; attempt++ {
+		if opts.Context != nil {
+			if err := opts.Context.Err(); err != nil {
+				return err
+			}
+		}
+
 		tx, err := d.BeginTransaction()
 		if err != nil {
 			return err
@@ -1053,22 +1337,33 @@ func (d *Database) RunTransaction(fn func(*Transaction) error) error {
 			return nil // Success!
 		}
 
-		// Check if it's a conflict
-		if err, ok := err.(*Error); ok && err.Message == "Transaction conflict" {
-			lastErr = err
-			// Retry with backoff
-			if attempt < config.MaxRetries {
-				backoff := config.RetryBackoffBaseMs * (1 << attempt)
-				if backoff > config.MaxRetryBackoffMs {
-					backoff = config.MaxRetryBackoffMs
-				}
-				if backoff > 0 {
-					// time.Sleep(time.Duration(backoff) * time.Millisecond)
-					// For now, no sleep in Go binding - could import time if needed
+		if !shouldRetry(err) {
+			return err
+		}
+		lastErr = err
+
+		if opts.OnRetry != nil {
+			opts.OnRetry(attempt, err)
+		}
+
+		if attempt < config.MaxRetries {
+			backoffMs := config.RetryBackoffBaseMs * (1 << attempt)
+			if backoffMs > config.MaxRetryBackoffMs {
+				backoffMs = config.MaxRetryBackoffMs
+			}
+			if backoffMs > 0 {
+				jitterMs := rand.Int63n(int64(backoffMs)/2 + 1)
+				sleep := time.Duration(int64(backoffMs)+jitterMs) * time.Millisecond
+				if opts.Context != nil {
+					select {
+					case <-time.After(sleep):
+					case <-opts.Context.Done():
+						return opts.Context.Err()
+					}
+				} else {
+					time.Sleep(sleep)
 				}
 			}
-		} else {
-			return err // Non-conflict error
 		}
 	}
 
@@ -1178,6 +1473,354 @@ func (t *Transaction) Count(collectionName string) (uint64, error) {
 	return uint64(count), nil
 }
 
+// ============================================================================
+// Aggregation Pipeline (Transaction)
+// ============================================================================
+
+// Stage is a single aggregation pipeline stage, keyed by the operator the
+// core understands: "match", "group_by", "sort", "limit", "skip",
+// "project", or "exclude". Lookup, Unwind and AddFields build "$lookup",
+// "$unwind" and "$addFields" stages, which run on the Go side instead (see
+// goStageKeys). Pipelines are an ordered []Stage rather than a
+// single map so stage order (which changes the result) survives JSON
+// encoding.
+type Stage map[string]interface{}
+
+// Match returns a "match" stage, filtering documents by filter (the same
+// query-object syntax Collection.Find accepts).
+func Match(filter string) Stage { return Stage{"match": filter} }
+
+// Project returns a "project" stage, keeping only the named fields.
+func Project(fields ...string) Stage { return Stage{"project": fields} }
+
+// Exclude returns an "exclude" stage, dropping the named fields and
+// keeping everything else.
+func Exclude(fields ...string) Stage { return Stage{"exclude": fields} }
+
+// Accumulator is one named output field of a Group stage, computed over
+// the documents in each group. Set exactly one of Sum, Avg, Min, Max, or
+// set Count.
+type Accumulator struct {
+	Sum   string
+	Avg   string
+	Min   string
+	Max   string
+	Count bool
+}
+
+// accumulatorStage converts accumulators, keyed by output field name, into
+// the []{"type", "output_field", "field"} shape the core's group_by stage
+// expects.
+func accumulatorStage(accumulators map[string]Accumulator) []map[string]interface{} {
+	stages := make([]map[string]interface{}, 0, len(accumulators))
+	for outputField, acc := range accumulators {
+		switch {
+		case acc.Count:
+			stages = append(stages, map[string]interface{}{"type": "count", "output_field": outputField})
+		case acc.Sum != "":
+			stages = append(stages, map[string]interface{}{"type": "sum", "output_field": outputField, "field": acc.Sum})
+		case acc.Avg != "":
+			stages = append(stages, map[string]interface{}{"type": "avg", "output_field": outputField, "field": acc.Avg})
+		case acc.Min != "":
+			stages = append(stages, map[string]interface{}{"type": "min", "output_field": outputField, "field": acc.Min})
+		case acc.Max != "":
+			stages = append(stages, map[string]interface{}{"type": "max", "output_field": outputField, "field": acc.Max})
+		}
+	}
+	return stages
+}
+
+// Group returns a "group_by" stage: field names the field to group by, and
+// accumulators names each output field to compute per group.
+func Group(field string, accumulators map[string]Accumulator) Stage {
+	return Stage{"group_by": map[string]interface{}{
+		"field":        field,
+		"accumulators": accumulatorStage(accumulators),
+	}}
+}
+
+// Sort returns a "sort" stage, ordering by field ascending if asc.
+func Sort(field string, asc bool) Stage {
+	return Stage{"sort": map[string]interface{}{"field": field, "ascending": asc}}
+}
+
+// Limit returns a "limit" stage, capping the pipeline at n documents.
+func Limit(n int) Stage { return Stage{"limit": n} }
+
+// Skip returns a "skip" stage, dropping the first n documents.
+func Skip(n int) Stage { return Stage{"skip": n} }
+
+// goStageKeys are the Stage keys run entirely in Go rather than sent
+// across the CGo boundary — the core's aggregate stage set is fixed to
+// match/group_by/sort/limit/skip/project/exclude, with no join,
+// array-flattening, or computed-field support, and no expression
+// evaluator to add one against. $lookup, $unwind, and $addFields only
+// run after every native stage in a pipeline has finished, operating on
+// the documents the core returned; see Aggregate's doc comment.
+var goStageKeys = map[string]bool{"$lookup": true, "$unwind": true, "$addFields": true}
+
+// LookupStage performs a left outer join against another collection,
+// matching each document's LocalField against matching documents'
+// ForeignField in From, and storing the matches (possibly empty) as an
+// array under As.
+type LookupStage struct {
+	From         string
+	LocalField   string
+	ForeignField string
+	As           string
+}
+
+// Lookup returns a Go-side "$lookup" stage. There's no
+// jasonisnthappy_collection_aggregate support for joins, so this doesn't
+// ship to the core as part of the pipeline JSON — it runs after the
+// native stages finish, querying From directly via Collection.Find once
+// per document.
+func Lookup(from, localField, foreignField, as string) Stage {
+	return Stage{"$lookup": LookupStage{From: from, LocalField: localField, ForeignField: foreignField, As: as}}
+}
+
+// UnwindStage flattens an array field, emitting one document per
+// element of Path and dropping documents where it's missing or empty
+// unless PreserveNullAndEmptyArrays is set.
+type UnwindStage struct {
+	Path                       string
+	PreserveNullAndEmptyArrays bool
+}
+
+// Unwind returns a Go-side "$unwind" stage; see Lookup's doc comment for
+// why this runs in Go instead of the core.
+func Unwind(path string, preserveNullAndEmptyArrays bool) Stage {
+	return Stage{"$unwind": UnwindStage{Path: path, PreserveNullAndEmptyArrays: preserveNullAndEmptyArrays}}
+}
+
+// Expression computes a value for an AddFields stage. Set exactly one
+// of Const, Field, or Op (with Args).
+type Expression struct {
+	// Const is a literal value.
+	Const interface{}
+	// Field references another field on the same document, "$"-prefixed
+	// the way the rest of this package's query syntax writes field
+	// references (e.g. "$price").
+	Field string
+	// Op is "add", "sub", "mul", "div", or "concat", applied to Args in
+	// order. add/sub/mul/div require numeric operands; concat stringifies
+	// and joins its operands.
+	Op   string
+	Args []Expression
+}
+
+// AddFields returns a Go-side "$addFields" stage computing each named
+// field from its Expression; see Lookup's doc comment for why this runs
+// in Go instead of the core.
+func AddFields(fields map[string]Expression) Stage {
+	return Stage{"$addFields": fields}
+}
+
+func evalExpression(e Expression, doc map[string]interface{}) (interface{}, error) {
+	switch {
+	case e.Op != "":
+		return evalOp(e.Op, e.Args, doc)
+	case e.Field != "":
+		return doc[strings.TrimPrefix(e.Field, "$")], nil
+	default:
+		return e.Const, nil
+	}
+}
+
+func evalOp(op string, args []Expression, doc map[string]interface{}) (interface{}, error) {
+	if op == "concat" {
+		var b strings.Builder
+		for _, a := range args {
+			v, err := evalExpression(a, doc)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprint(&b, v)
+		}
+		return b.String(), nil
+	}
+
+	if len(args) == 0 {
+		return nil, &Error{Code: -1, Message: "aggregate: $addFields op " + op + " requires at least one argument"}
+	}
+
+	vals := make([]float64, len(args))
+	for i, a := range args {
+		v, err := evalExpression(a, doc)
+		if err != nil {
+			return nil, err
+		}
+		f, ok := toFloat64(v)
+		if !ok {
+			return nil, &Error{Code: -1, Message: "aggregate: $addFields op " + op + ": non-numeric operand"}
+		}
+		vals[i] = f
+	}
+
+	total := vals[0]
+	for _, v := range vals[1:] {
+		switch op {
+		case "add":
+			total += v
+		case "sub":
+			total -= v
+		case "mul":
+			total *= v
+		case "div":
+			total /= v
+		default:
+			return nil, &Error{Code: -1, Message: "aggregate: unknown $addFields op " + op}
+		}
+	}
+	return total, nil
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// splitPipeline separates pipeline into its leading run of core-native
+// stages and its trailing run of Go-side stages (see goStageKeys). The
+// core has no way to resume aggregating from a Go-computed document
+// set, so a native stage that follows a Go-side one is rejected instead
+// of silently running in the wrong place.
+func splitPipeline(pipeline []Stage) (native, goSide []Stage, err error) {
+	seenGoStage := false
+	for i, stage := range pipeline {
+		isGoStage := false
+		for k := range stage {
+			if goStageKeys[k] {
+				isGoStage = true
+				break
+			}
+		}
+		if isGoStage {
+			seenGoStage = true
+			goSide = append(goSide, stage)
+			continue
+		}
+		if seenGoStage {
+			return nil, nil, &Error{Code: -1, Message: fmt.Sprintf(
+				"aggregate: stage %d runs on the core but follows a $lookup/$unwind/$addFields stage, which isn't supported", i)}
+		}
+		native = append(native, stage)
+	}
+	return native, goSide, nil
+}
+
+func applyGoStage(c *Collection, stage Stage, docs []map[string]interface{}) ([]map[string]interface{}, error) {
+	switch {
+	case stage["$lookup"] != nil:
+		lookup, ok := stage["$lookup"].(LookupStage)
+		if !ok {
+			return nil, &Error{Code: -1, Message: "aggregate: malformed $lookup stage"}
+		}
+		return applyLookup(c, lookup, docs)
+	case stage["$unwind"] != nil:
+		unwind, ok := stage["$unwind"].(UnwindStage)
+		if !ok {
+			return nil, &Error{Code: -1, Message: "aggregate: malformed $unwind stage"}
+		}
+		return applyUnwind(unwind, docs), nil
+	case stage["$addFields"] != nil:
+		fields, ok := stage["$addFields"].(map[string]Expression)
+		if !ok {
+			return nil, &Error{Code: -1, Message: "aggregate: malformed $addFields stage"}
+		}
+		return applyAddFields(fields, docs)
+	default:
+		return nil, &Error{Code: -1, Message: "aggregate: unrecognized Go-side stage"}
+	}
+}
+
+func applyLookup(c *Collection, l LookupStage, docs []map[string]interface{}) ([]map[string]interface{}, error) {
+	foreign, err := c.db.GetCollection(l.From)
+	if err != nil {
+		return nil, err
+	}
+	defer foreign.Free()
+
+	for _, doc := range docs {
+		localVal, ok := doc[l.LocalField]
+		if !ok {
+			doc[l.As] = []interface{}{}
+			continue
+		}
+
+		filterJSON, err := json.Marshal(map[string]interface{}{l.ForeignField: localVal})
+		if err != nil {
+			return nil, err
+		}
+
+		var matches []map[string]interface{}
+		if err := foreign.Find(string(filterJSON), &matches); err != nil {
+			return nil, err
+		}
+		if matches == nil {
+			matches = []map[string]interface{}{}
+		}
+		doc[l.As] = matches
+	}
+	return docs, nil
+}
+
+func applyUnwind(u UnwindStage, docs []map[string]interface{}) []map[string]interface{} {
+	path := strings.TrimPrefix(u.Path, "$")
+
+	out := make([]map[string]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		arr, ok := doc[path].([]interface{})
+		if !ok || len(arr) == 0 {
+			if u.PreserveNullAndEmptyArrays {
+				out = append(out, cloneDocWithField(doc, path, nil))
+			}
+			continue
+		}
+		for _, item := range arr {
+			out = append(out, cloneDocWithField(doc, path, item))
+		}
+	}
+	return out
+}
+
+func cloneDocWithField(doc map[string]interface{}, field string, value interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		clone[k] = v
+	}
+	clone[field] = value
+	return clone
+}
+
+func applyAddFields(fields map[string]Expression, docs []map[string]interface{}) ([]map[string]interface{}, error) {
+	for _, doc := range docs {
+		for name, expr := range fields {
+			v, err := evalExpression(expr, doc)
+			if err != nil {
+				return nil, err
+			}
+			doc[name] = v
+		}
+	}
+	return docs, nil
+}
+
 // ============================================================================
 // Collection API
 // ============================================================================
@@ -1185,9 +1828,19 @@ func (t *Transaction) Count(collectionName string) (uint64, error) {
 // Collection represents a database collection for non-transactional operations
 type Collection struct {
 	coll *C.CCollection
+	db   *Database
 }
 
-// GetCollection gets a collection handle for non-transactional operations
+// GetCollection gets a collection handle for non-transactional operations.
+//
+// There's no Database.CreateView/DropView: the core has no view or
+// materialization concept at all, so GetCollection only ever resolves a
+// real, named collection created via CreateCollection. A prior pass
+// wired views to jasonisnthappy_create_view/_drop_view/_refresh_view,
+// none of which the FFI exports, and was reverted. Reusable named
+// queries still have to be plain Go helper functions wrapping Aggregate
+// or Find against the source collection; this request is withdrawn
+// rather than reattempted against core support that isn't there.
 func (d *Database) GetCollection(name string) (*Collection, error) {
 	if d.db == nil {
 		return nil, &Error{Code: -1, Message: "Database is closed"}
@@ -1205,7 +1858,7 @@ func (d *Database) GetCollection(name string) (*Collection, error) {
 		return nil, err
 	}
 
-	return &Collection{coll: coll}, nil
+	return &Collection{coll: coll, db: d}, nil
 }
 
 // Free releases the collection handle
@@ -1756,6 +2409,251 @@ func (c *Collection) Search(query string, result interface{}) error {
 	return json.Unmarshal([]byte(jsonStr), result)
 }
 
+// RankingModel selects how SearchWithOptions scores a document against
+// a query.
+type RankingModel int
+
+const (
+	// RankingBM25 scores with Okapi BM25 (k1=1.2, b=0.75).
+	RankingBM25 RankingModel = iota
+	// RankingTFIDF scores with classic TF-IDF.
+	RankingTFIDF
+)
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Span is a byte-offset range within a field's text where a query term
+// was found, for rendering a highlight.
+type Span struct {
+	Start int
+	End   int
+}
+
+// SearchOptions configures a Collection.SearchWithOptions call.
+type SearchOptions struct {
+	Limit     int
+	Offset    int
+	Fields    []string
+	Highlight bool
+	MinScore  float64
+	Ranking   RankingModel
+}
+
+// SearchHit is one ranked result from SearchWithOptions.
+type SearchHit struct {
+	Doc        json.RawMessage
+	Score      float64
+	Highlights map[string][]Span
+}
+
+// SearchWithOptions runs a full-text query like Search, but returns
+// ranked SearchHits carrying a relevance score (and optionally
+// highlight spans) instead of plain, unordered matches.
+//
+// There's no jasonisnthappy_collection_search_with_options in the FFI —
+// the core's search call returns only unordered matches with no score
+// or term-position data. A prior pass added SearchWithOptions/SearchHit
+// wired to that nonexistent entry point and was reverted. This version
+// calls the real Search and does the ranking and highlighting in Go
+// against its results: document frequency is approximated from the
+// matched set itself, since there's no global term-frequency index to
+// query exactly, and N is Collection.Count, the total corpus size.
+func (c *Collection) SearchWithOptions(query string, opts SearchOptions) ([]SearchHit, error) {
+	if c.coll == nil {
+		return nil, &Error{Code: -1, Message: "Collection is closed"}
+	}
+
+	var docs []json.RawMessage
+	if err := c.Search(query, &docs); err != nil {
+		return nil, err
+	}
+
+	queryTerms := searchTokenize(query)
+	if len(queryTerms) == 0 || len(docs) == 0 {
+		return nil, nil
+	}
+
+	total, err := c.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	type docEntry struct {
+		doc    json.RawMessage
+		fields map[string]string
+		tokens map[string][]string
+		docLen int
+	}
+
+	entries := make([]docEntry, len(docs))
+	df := make(map[string]int)
+	totalLen := 0
+
+	for i, d := range docs {
+		fields := searchFieldText(d, opts.Fields)
+		tokens := make(map[string][]string, len(fields))
+		docLen := 0
+		seen := make(map[string]bool)
+		for name, text := range fields {
+			toks := searchTokenize(text)
+			tokens[name] = toks
+			docLen += len(toks)
+			for _, t := range toks {
+				seen[t] = true
+			}
+		}
+		for t := range seen {
+			df[t]++
+		}
+		entries[i] = docEntry{doc: d, fields: fields, tokens: tokens, docLen: docLen}
+		totalLen += docLen
+	}
+
+	avgDocLen := float64(totalLen) / float64(len(docs))
+	if avgDocLen == 0 {
+		avgDocLen = 1
+	}
+
+	hits := make([]SearchHit, 0, len(docs))
+	for _, e := range entries {
+		tf := make(map[string]int)
+		for _, toks := range e.tokens {
+			for _, t := range toks {
+				tf[t]++
+			}
+		}
+
+		var score float64
+		for _, term := range queryTerms {
+			f := tf[term]
+			d := df[term]
+			if f == 0 || d == 0 {
+				continue
+			}
+			switch opts.Ranking {
+			case RankingTFIDF:
+				score += float64(f) * math.Log(1+float64(total)/float64(d))
+			default: // RankingBM25
+				idf := math.Log((float64(total)-float64(d)+0.5)/(float64(d)+0.5) + 1)
+				denom := float64(f) + bm25K1*(1-bm25B+bm25B*float64(e.docLen)/avgDocLen)
+				score += idf * (float64(f) * (bm25K1 + 1)) / denom
+			}
+		}
+
+		if score < opts.MinScore {
+			continue
+		}
+
+		hit := SearchHit{Doc: e.doc, Score: score}
+		if opts.Highlight {
+			hit.Highlights = searchHighlights(e.fields, queryTerms)
+		}
+		hits = append(hits, hit)
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+
+	if opts.Offset > 0 {
+		if opts.Offset >= len(hits) {
+			return nil, nil
+		}
+		hits = hits[opts.Offset:]
+	}
+	if opts.Limit > 0 && opts.Limit < len(hits) {
+		hits = hits[:opts.Limit]
+	}
+
+	return hits, nil
+}
+
+// searchTokenize lowercases s and splits it on runs of non-alphanumeric
+// characters. The query and every candidate document's field text go
+// through the same tokenizer so term frequencies line up.
+func searchTokenize(s string) []string {
+	var tokens []string
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			continue
+		}
+		if b.Len() > 0 {
+			tokens = append(tokens, b.String())
+			b.Reset()
+		}
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+	return tokens
+}
+
+// searchFieldText extracts a document's string and string-array fields,
+// restricted to fieldNames if non-empty.
+func searchFieldText(raw json.RawMessage, fieldNames []string) map[string]string {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil
+	}
+
+	want := make(map[string]bool, len(fieldNames))
+	for _, f := range fieldNames {
+		want[f] = true
+	}
+
+	fields := make(map[string]string)
+	for k, v := range doc {
+		if len(want) > 0 && !want[k] {
+			continue
+		}
+		switch val := v.(type) {
+		case string:
+			fields[k] = val
+		case []interface{}:
+			var parts []string
+			for _, item := range val {
+				if s, ok := item.(string); ok {
+					parts = append(parts, s)
+				}
+			}
+			if len(parts) > 0 {
+				fields[k] = strings.Join(parts, " ")
+			}
+		}
+	}
+	return fields
+}
+
+// searchHighlights finds each query term's byte-offset occurrences
+// within fields, case-insensitively.
+func searchHighlights(fields map[string]string, terms []string) map[string][]Span {
+	highlights := make(map[string][]Span)
+	for name, text := range fields {
+		lower := strings.ToLower(text)
+		var spans []Span
+		for _, term := range terms {
+			start := 0
+			for {
+				idx := strings.Index(lower[start:], term)
+				if idx < 0 {
+					break
+				}
+				s := start + idx
+				spans = append(spans, Span{Start: s, End: s + len(term)})
+				start = s + len(term)
+			}
+		}
+		if len(spans) > 0 {
+			sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+			highlights[name] = spans
+		}
+	}
+	return highlights
+}
+
 // CountWithQuery counts documents matching a filter
 func (c *Collection) CountWithQuery(filter string) (uint64, error) {
 	if c.coll == nil {
@@ -1947,6 +2845,75 @@ func (c *Collection) QueryCount(filter string, skip uint64, limit uint64) (uint6
 	return uint64(count), nil
 }
 
+// PageOptions configures a single Collection.Paginate call.
+type PageOptions struct {
+	Filter  string
+	Sort    string
+	SortAsc bool
+	Project []string
+	Exclude []string
+
+	// Page is the 1-indexed page number; values <= 0 are treated as 1.
+	Page int64
+	// Size is the number of documents per page.
+	Size int64
+}
+
+// PageResult is the result of a Collection.Paginate call.
+type PageResult struct {
+	List    json.RawMessage `json:"list"`
+	Total   uint64          `json:"total"`
+	Page    int64           `json:"page"`
+	Size    int64           `json:"size"`
+	HasMore bool            `json:"has_more"`
+}
+
+// Paginate returns one page of documents matching opts.Filter along with
+// the total matching count, decoding the page into out if out is
+// non-nil. The page and the count are two separate FFI calls (QueryWithOptions
+// then QueryCount), so Total can drift from List if another writer commits
+// between them; callers that need a single consistent snapshot should wrap
+// the call in a transaction's equivalent reads instead.
+func (c *Collection) Paginate(opts PageOptions, out interface{}) (*PageResult, error) {
+	if c.coll == nil {
+		return nil, &Error{Code: -1, Message: "Collection is closed"}
+	}
+
+	page := opts.Page
+	if page <= 0 {
+		page = 1
+	}
+	size := opts.Size
+	if size <= 0 {
+		size = 20
+	}
+	skip := uint64(page-1) * uint64(size)
+
+	var list json.RawMessage
+	if err := c.QueryWithOptions(opts.Filter, opts.Sort, opts.SortAsc, uint64(size), skip, opts.Project, opts.Exclude, &list); err != nil {
+		return nil, err
+	}
+
+	total, err := c.QueryCount(opts.Filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(list, out); err != nil {
+			return nil, err
+		}
+	}
+
+	return &PageResult{
+		List:    list,
+		Total:   total,
+		Page:    page,
+		Size:    size,
+		HasMore: uint64(page)*uint64(size) < total,
+	}, nil
+}
+
 // QueryFirst gets the first document matching a filter
 func (c *Collection) QueryFirst(filter string, sortField string, sortAsc bool, result interface{}) (bool, error) {
 	if c.coll == nil {
@@ -1996,40 +2963,265 @@ func (c *Collection) QueryFirst(filter string, sortField string, sortAsc bool, r
 	return true, nil
 }
 
+// IterOptions configures a Collection.Iterate call.
+type IterOptions struct {
+	Filter  string
+	Sort    string
+	SortAsc bool
+	Project []string
+	Exclude []string
+
+	// BatchSize caps how many documents Iterate fetches per underlying
+	// QueryWithOptions call; Next decodes documents out of the current
+	// batch one at a time, only crossing the CGo boundary again once the
+	// batch runs out. Defaults to 256.
+	BatchSize int
+}
+
+// Cursor streams a Collection's matching documents in batches instead of
+// materializing the whole result set up front, the way FindAll does.
+// There's no FFI-level equivalent of LevelDB's Seek/Prev/Last — the core
+// only exposes forward, offset-based paging via
+// jasonisnthappy_collection_query_with_options — so Cursor is a
+// forward-only iterator in the mgo Iter.Next mould: call Next in a loop
+// until it returns false, then check Err to tell exhaustion from failure.
+type Cursor struct {
+	coll *Collection
+	opts IterOptions
+	skip uint64
+
+	batch []json.RawMessage
+	idx   int
+	cur   json.RawMessage
+
+	done bool
+	err  error
+}
+
+// Iterate returns a Cursor over the documents matching opts.Filter,
+// fetching them from the core BatchSize documents at a time rather than
+// all at once.
+func (c *Collection) Iterate(opts IterOptions) (*Cursor, error) {
+	if c.coll == nil {
+		return nil, &Error{Code: -1, Message: "Collection is closed"}
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 256
+	}
+	return &Cursor{coll: c, opts: opts}, nil
+}
+
+// Next advances the cursor to the next document, fetching the next
+// batch from the core once the current one is exhausted. It returns
+// false when the result set is exhausted or an error occurs — call Err
+// afterward to tell the two apart.
+func (cur *Cursor) Next() bool {
+	if cur.done || cur.err != nil {
+		return false
+	}
+
+	if cur.idx >= len(cur.batch) {
+		var page []json.RawMessage
+		err := cur.coll.QueryWithOptions(
+			cur.opts.Filter, cur.opts.Sort, cur.opts.SortAsc,
+			uint64(cur.opts.BatchSize), cur.skip,
+			cur.opts.Project, cur.opts.Exclude,
+			&page,
+		)
+		if err != nil {
+			cur.err = err
+			return false
+		}
+		if len(page) == 0 {
+			cur.done = true
+			return false
+		}
+		cur.batch = page
+		cur.idx = 0
+		cur.skip += uint64(len(page))
+	}
+
+	cur.cur = cur.batch[cur.idx]
+	cur.idx++
+	return true
+}
+
+// Decode unmarshals the document at the cursor's current position into
+// v. It's only valid to call after a Next that returned true.
+func (cur *Cursor) Decode(v interface{}) error {
+	if cur.cur == nil {
+		return &Error{Code: -1, Message: "Cursor: Decode called before a successful Next"}
+	}
+	return json.Unmarshal(cur.cur, v)
+}
+
+// Err returns the first error Next encountered, if any.
+func (cur *Cursor) Err() error {
+	return cur.err
+}
+
+// Close releases the cursor. Iterate holds no FFI-side resources between
+// batches, so this never contacts the core — it's provided so callers
+// can defer it symmetrically with other result-set APIs.
+func (cur *Cursor) Close() error {
+	cur.done = true
+	return nil
+}
+
 // ====================
 // Bulk Write Operations
 // ====================
 
-// BulkWriteOperation represents a single bulk operation
-type BulkWriteOperation struct {
-	Op     string                 `json:"op"`
-	Filter string                 `json:"filter,omitempty"`
-	Doc    map[string]interface{} `json:"doc,omitempty"`
-	Update map[string]interface{} `json:"update,omitempty"`
+// BulkOp is a single operation queued onto a Collection.BulkWrite call.
+// It is implemented by BulkInsert, BulkUpdateByID, BulkUpdateOne,
+// BulkUpdateMany, BulkUpsert, BulkDeleteByID, BulkDeleteOne, and
+// BulkDeleteMany.
+type BulkOp interface {
+	bulkOp() bulkWriteOp
 }
 
-// BulkWriteError represents an error from a bulk write operation
-type BulkWriteError struct {
-	OperationIndex int    `json:"operation_index"`
-	Message        string `json:"message"`
+// bulkWriteOp is the wire representation of a BulkOp, shared by every
+// concrete op type below.
+type bulkWriteOp struct {
+	Op     string      `json:"op"`
+	ID     string      `json:"id,omitempty"`
+	Filter string      `json:"filter,omitempty"`
+	Doc    interface{} `json:"doc,omitempty"`
+	Update interface{} `json:"update,omitempty"`
+	Upsert bool        `json:"upsert,omitempty"`
 }
 
-// BulkWriteResult contains the result of a bulk write operation
-type BulkWriteResult struct {
-	InsertedCount int              `json:"inserted_count"`
-	UpdatedCount  int              `json:"updated_count"`
-	DeletedCount  int              `json:"deleted_count"`
-	InsertedIDs   []string         `json:"inserted_ids,omitempty"`
-	Errors        []BulkWriteError `json:"errors,omitempty"`
+// BulkInsert inserts Doc as a new document.
+type BulkInsert struct{ Doc interface{} }
+
+func (o BulkInsert) bulkOp() bulkWriteOp { return bulkWriteOp{Op: "insert", Doc: o.Doc} }
+
+// BulkUpdateByID replaces the document with the given ID with Update.
+type BulkUpdateByID struct {
+	ID     string
+	Update interface{}
+}
+
+func (o BulkUpdateByID) bulkOp() bulkWriteOp {
+	return bulkWriteOp{Op: "update_by_id", ID: o.ID, Update: o.Update}
+}
+
+// BulkUpdateOne applies Update to the first document matching Filter.
+type BulkUpdateOne struct {
+	Filter string
+	Update interface{}
+}
+
+func (o BulkUpdateOne) bulkOp() bulkWriteOp {
+	return bulkWriteOp{Op: "update_one", Filter: o.Filter, Update: o.Update}
+}
+
+// BulkUpdateMany applies Update to every document matching Filter,
+// inserting it as a new document if nothing matches and Upsert is true.
+type BulkUpdateMany struct {
+	Filter string
+	Update interface{}
+	Upsert bool
+}
+
+func (o BulkUpdateMany) bulkOp() bulkWriteOp {
+	return bulkWriteOp{Op: "update_many", Filter: o.Filter, Update: o.Update, Upsert: o.Upsert}
 }
 
-// BulkWrite executes multiple operations in a single transaction
-func (c *Collection) BulkWrite(operations []BulkWriteOperation, ordered bool) (*BulkWriteResult, error) {
+// BulkUpsert updates the document matching Filter with Doc, inserting Doc
+// as a new document if nothing matches.
+type BulkUpsert struct {
+	Filter string
+	Doc    interface{}
+}
+
+func (o BulkUpsert) bulkOp() bulkWriteOp {
+	return bulkWriteOp{Op: "upsert", Filter: o.Filter, Doc: o.Doc, Upsert: true}
+}
+
+// BulkDeleteByID deletes the document with the given ID.
+type BulkDeleteByID struct{ ID string }
+
+func (o BulkDeleteByID) bulkOp() bulkWriteOp { return bulkWriteOp{Op: "delete_by_id", ID: o.ID} }
+
+// BulkDeleteOne deletes the first document matching Filter.
+type BulkDeleteOne struct{ Filter string }
+
+func (o BulkDeleteOne) bulkOp() bulkWriteOp { return bulkWriteOp{Op: "delete_one", Filter: o.Filter} }
+
+// BulkDeleteMany deletes every document matching Filter.
+type BulkDeleteMany struct{ Filter string }
+
+func (o BulkDeleteMany) bulkOp() bulkWriteOp { return bulkWriteOp{Op: "delete_many", Filter: o.Filter} }
+
+// BulkOptions controls how Collection.BulkWrite handles a failing
+// operation partway through the batch.
+type BulkOptions struct {
+	// Ordered stops at the first failing operation, leaving the rest
+	// unexecuted, when true. When false, every operation runs regardless
+	// of earlier failures and each failure is reported in
+	// BulkWriteResult.Cases.
+	Ordered bool
+}
+
+// BulkErrorCase describes one operation in a BulkWrite batch that failed.
+type BulkErrorCase struct {
+	Index int
+	Op    string
+	Err   error
+}
+
+// BulkWriteResult contains the result of a Collection.BulkWrite call.
+// Matched and Modified report, by operation index, how many documents
+// each update/upsert op touched, and Cases reports which ops failed —
+// both are how callers reconcile which of an unordered batch's
+// operations actually took effect.
+type BulkWriteResult struct {
+	InsertedIDs   []string
+	MatchedCount  uint64
+	ModifiedCount uint64
+	DeletedCount  uint64
+	UpsertedIDs   []string
+	Matched       []int
+	Modified      []int
+	Cases         []BulkErrorCase
+}
+
+// bulkWriteResultWire is the JSON shape returned by
+// jasonisnthappy_collection_bulk_write. BulkWrite unpacks it into a
+// BulkWriteResult, turning each wire case's code/message pair into an
+// *Error so Cases[i].Err satisfies the standard error interface.
+type bulkWriteResultWire struct {
+	InsertedIDs   []string `json:"inserted_ids,omitempty"`
+	MatchedCount  uint64   `json:"matched_count"`
+	ModifiedCount uint64   `json:"modified_count"`
+	DeletedCount  uint64   `json:"deleted_count"`
+	UpsertedIDs   []string `json:"upserted_ids,omitempty"`
+	Matched       []int    `json:"matched,omitempty"`
+	Modified      []int    `json:"modified,omitempty"`
+	Cases         []struct {
+		Index   int    `json:"index"`
+		Op      string `json:"op"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"cases,omitempty"`
+}
+
+// BulkWrite executes ops as a single FFI call committing as one
+// transaction, so an ordered batch is all-or-nothing up to its first
+// failure and an unordered batch is atomic as a whole despite reporting
+// individual op failures in the result.
+func (c *Collection) BulkWrite(ops []BulkOp, opts BulkOptions) (*BulkWriteResult, error) {
 	if c.coll == nil {
 		return nil, &Error{Code: -1, Message: "Collection is closed"}
 	}
 
-	opsJSON, err := json.Marshal(operations)
+	wireOps := make([]bulkWriteOp, len(ops))
+	for i, op := range ops {
+		wireOps[i] = op.bulkOp()
+	}
+
+	opsJSON, err := json.Marshal(wireOps)
 	if err != nil {
 		return nil, err
 	}
@@ -2039,7 +3231,7 @@ func (c *Collection) BulkWrite(operations []BulkWriteOperation, ordered bool) (*
 
 	var cResult *C.char
 	var cErr C.CError
-	status := C.jasonisnthappy_collection_bulk_write(c.coll, cOps, C.bool(ordered), &cResult, &cErr)
+	status := C.jasonisnthappy_collection_bulk_write(c.coll, cOps, C.bool(opts.Ordered), &cResult, &cErr)
 
 	if status != 0 {
 		err := cErrorToGoError(&cErr)
@@ -2050,57 +3242,150 @@ func (c *Collection) BulkWrite(operations []BulkWriteOperation, ordered bool) (*
 	resultStr := C.GoString(cResult)
 	C.jasonisnthappy_free_string(cResult)
 
-	var result BulkWriteResult
-	if err := json.Unmarshal([]byte(resultStr), &result); err != nil {
+	var wire bulkWriteResultWire
+	if err := json.Unmarshal([]byte(resultStr), &wire); err != nil {
 		return nil, err
 	}
 
-	return &result, nil
+	return wire.toResult(), nil
 }
 
-// ====================
-// Aggregation Pipeline
-// ====================
+// toResult converts the FFI wire shape into a BulkWriteResult, turning
+// each wire case's code/message pair into an *Error.
+func (w bulkWriteResultWire) toResult() *BulkWriteResult {
+	result := &BulkWriteResult{
+		InsertedIDs:   w.InsertedIDs,
+		MatchedCount:  w.MatchedCount,
+		ModifiedCount: w.ModifiedCount,
+		DeletedCount:  w.DeletedCount,
+		UpsertedIDs:   w.UpsertedIDs,
+		Matched:       w.Matched,
+		Modified:      w.Modified,
+	}
+	for _, c := range w.Cases {
+		result.Cases = append(result.Cases, BulkErrorCase{
+			Index: c.Index,
+			Op:    c.Op,
+			Err:   &Error{Code: c.Code, Message: c.Message},
+		})
+	}
+	return result
+}
+
+// Bulk batches a sequence of write operations queued via NewOrderedBulk
+// or NewUnorderedBulk, executed together by Run.
+//
+// This is also the answer for high-throughput bulk ingest without
+// per-op CGo overhead: Run ships the whole queued batch to
+// jasonisnthappy_collection_bulk_write in one call, instead of one CGo
+// crossing and two C.CStrings per Insert/UpdateByID/DeleteByID. A
+// separate WriteBatch type with its own framing and a new
+// jasonisnthappy_apply_batch entry point was attempted for this, against
+// FFI that was never exported, and reverted. Bulk already solves the
+// same problem with an entry point that's actually there, so it isn't
+// being reattempted under a different name.
+type Bulk struct {
+	coll    *Collection
+	ordered bool
+	ops     []BulkOp
+}
+
+// NewOrderedBulk returns a Bulk that, on Run, stops at the first failing
+// operation and leaves the rest of the batch unexecuted.
+func (c *Collection) NewOrderedBulk() *Bulk {
+	return &Bulk{coll: c, ordered: true}
+}
+
+// NewUnorderedBulk returns a Bulk that, on Run, executes every queued
+// operation regardless of earlier failures, reporting each failure in
+// Run's BulkWriteResult.Cases.
+func (c *Collection) NewUnorderedBulk() *Bulk {
+	return &Bulk{coll: c}
+}
+
+// Insert queues doc to be inserted as a new document.
+func (b *Bulk) Insert(doc interface{}) {
+	b.ops = append(b.ops, BulkInsert{Doc: doc})
+}
+
+// Update queues update to be applied to the first document matching
+// filter.
+func (b *Bulk) Update(filter string, update interface{}) {
+	b.ops = append(b.ops, BulkUpdateOne{Filter: filter, Update: update})
+}
 
-// AggregationStage represents a single aggregation stage
-type AggregationStage struct {
-	Match     string              `json:"match,omitempty"`
-	GroupBy   string              `json:"group_by,omitempty"`
-	Count     string              `json:"count,omitempty"`
-	Sum       *AggregationField   `json:"sum,omitempty"`
-	Avg       *AggregationField   `json:"avg,omitempty"`
-	Min       *AggregationField   `json:"min,omitempty"`
-	Max       *AggregationField   `json:"max,omitempty"`
-	Sort      *SortOptions        `json:"sort,omitempty"`
-	Limit     int                 `json:"limit,omitempty"`
-	Skip      int                 `json:"skip,omitempty"`
-	Project   []string            `json:"project,omitempty"`
-	Exclude   []string            `json:"exclude,omitempty"`
+// Upsert queues doc to replace the document matching filter, inserting
+// doc as a new document if nothing matches.
+func (b *Bulk) Upsert(filter string, doc interface{}) {
+	b.ops = append(b.ops, BulkUpsert{Filter: filter, Doc: doc})
 }
 
-// AggregationField represents a field for aggregation functions
-type AggregationField struct {
-	Field  string `json:"field"`
-	Output string `json:"output"`
+// RemoveOne queues the removal of the first document matching filter.
+func (b *Bulk) RemoveOne(filter string) {
+	b.ops = append(b.ops, BulkDeleteOne{Filter: filter})
 }
 
-// SortOptions represents sort options for aggregation
-type SortOptions struct {
-	Field string `json:"field"`
-	Asc   bool   `json:"asc"`
+// RemoveAll queues the removal of every document matching filter.
+func (b *Bulk) RemoveAll(filter string) {
+	b.ops = append(b.ops, BulkDeleteMany{Filter: filter})
 }
 
-// Aggregate executes an aggregation pipeline
-func (c *Collection) Aggregate(pipeline []AggregationStage, result interface{}) error {
+// Run executes the queued operations via Collection.BulkWrite.
+func (b *Bulk) Run() (*BulkWriteResult, error) {
+	return b.coll.BulkWrite(b.ops, BulkOptions{Ordered: b.ordered})
+}
+
+// ====================
+// Aggregation Pipeline
+// ====================
+
+// Aggregate runs pipeline against the collection and decodes the
+// resulting documents into result. pipeline is built from the Match,
+// Project, Exclude, Group, Sort, Limit, and Skip stage constructors, in
+// the order they should run, plus the Go-side Lookup, Unwind, and
+// AddFields stages. The native stages run first, in one
+// jasonisnthappy_collection_aggregate call; any Lookup/Unwind/AddFields
+// stages then run afterward, in Go, against whatever the core returned.
+// A native stage after a Go-side one in pipeline is rejected — see
+// splitPipeline.
+func (c *Collection) Aggregate(pipeline []Stage, result interface{}) error {
 	if c.coll == nil {
 		return &Error{Code: -1, Message: "Collection is closed"}
 	}
 
-	pipelineJSON, err := json.Marshal(pipeline)
+	native, goSide, err := splitPipeline(pipeline)
+	if err != nil {
+		return err
+	}
+
+	docs, err := c.runNativeAggregate(native)
 	if err != nil {
 		return err
 	}
 
+	for _, stage := range goSide {
+		docs, err = applyGoStage(c, stage, docs)
+		if err != nil {
+			return err
+		}
+	}
+
+	docsJSON, err := json.Marshal(docs)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(docsJSON, result)
+}
+
+// runNativeAggregate sends stages to jasonisnthappy_collection_aggregate
+// and decodes the resulting documents generically, for further Go-side
+// processing.
+func (c *Collection) runNativeAggregate(stages []Stage) ([]map[string]interface{}, error) {
+	pipelineJSON, err := json.Marshal(stages)
+	if err != nil {
+		return nil, err
+	}
+
 	cPipeline := C.CString(string(pipelineJSON))
 	defer C.free(unsafe.Pointer(cPipeline))
 
@@ -2111,13 +3396,17 @@ func (c *Collection) Aggregate(pipeline []AggregationStage, result interface{})
 	if status != 0 {
 		err := cErrorToGoError(&cErr)
 		C.jasonisnthappy_free_error(cErr)
-		return err
+		return nil, err
 	}
 
 	jsonStr := C.GoString(cJSON)
 	C.jasonisnthappy_free_string(cJSON)
 
-	return json.Unmarshal([]byte(jsonStr), result)
+	var docs []map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonStr), &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
 }
 
 // ====================
@@ -2176,6 +3465,27 @@ func (ws *WebServer) Stop() {
 type WatchHandle struct {
 	handle     *C.CWatchHandle
 	callbackID uintptr
+
+	// onStop, if set, is run once by Stop alongside the usual FFI/callback
+	// cleanup — used by WatchStartBatch to shut down its batching
+	// goroutine.
+	onStop func()
+}
+
+func registerWatchCallback(callback WatchCallback) (uintptr, *WatchHandle) {
+	watchCallbacksMu.Lock()
+	callbackID := nextCallbackID
+	nextCallbackID++
+	watchCallbacks[callbackID] = callback
+	watchCallbacksMu.Unlock()
+
+	return callbackID, &WatchHandle{callbackID: callbackID}
+}
+
+func unregisterWatchCallback(callbackID uintptr) {
+	watchCallbacksMu.Lock()
+	delete(watchCallbacks, callbackID)
+	watchCallbacksMu.Unlock()
 }
 
 // WatchStart starts watching for changes on the collection
@@ -2183,6 +3493,18 @@ type WatchHandle struct {
 // The callback will be called for each change event that matches the filter.
 // Pass an empty string for filter to watch all changes.
 //
+// There's no resume token: jasonisnthappy_collection_watch_start takes
+// no replay-from argument and emits no WAL position alongside its
+// events, and jasonisnthappy_frame_count reports a count, not a
+// seekable offset the watch call can restart from. A watcher that
+// crashes or reconnects has no way to ask for exactly what it missed —
+// this has been attempted twice in this package's history (once as
+// WatchResume/ResumeToken, once as WatchStartAfter/WatchStartAtTimestamp)
+// and reverted both times for the same reason. Both are withdrawn
+// rather than attempted a third time; a caller that needs this has to
+// build its own oplog-style collection and write to it from inside the
+// same transaction as the change it's recording.
+//
 // Example:
 //
 //	handle, err := collection.WatchStart("", func(coll, op, docID, docJSON string) {
@@ -2197,12 +3519,7 @@ func (c *Collection) WatchStart(filter string, callback WatchCallback) (*WatchHa
 		return nil, &Error{Code: -1, Message: "Collection is freed"}
 	}
 
-	// Register the callback
-	watchCallbacksMu.Lock()
-	callbackID := nextCallbackID
-	nextCallbackID++
-	watchCallbacks[callbackID] = callback
-	watchCallbacksMu.Unlock()
+	callbackID, handle := registerWatchCallback(callback)
 
 	// Prepare filter
 	var cFilter *C.char
@@ -2225,20 +3542,138 @@ func (c *Collection) WatchStart(filter string, callback WatchCallback) (*WatchHa
 	)
 
 	if result != 0 {
-		// Cleanup callback registration on error
-		watchCallbacksMu.Lock()
-		delete(watchCallbacks, callbackID)
-		watchCallbacksMu.Unlock()
+		unregisterWatchCallback(callbackID)
 
 		err := cErrorToGoError(&cErr)
 		C.jasonisnthappy_free_error(cErr)
 		return nil, err
 	}
 
-	return &WatchHandle{
-		handle:     cHandle,
-		callbackID: callbackID,
-	}, nil
+	handle.handle = cHandle
+	return handle, nil
+}
+
+// WatchOperation is a bitmask identifying which kinds of change events a
+// watch subscription should deliver.
+type WatchOperation uint8
+
+const (
+	WatchInsert WatchOperation = 1 << iota
+	WatchUpdate
+	WatchDelete
+)
+
+// WatchAllOperations delivers inserts, updates, and deletes.
+const WatchAllOperations = WatchInsert | WatchUpdate | WatchDelete
+
+// WatchOptions configures a filtered watch subscription.
+type WatchOptions struct {
+	// Predicate is a document filter (the same query syntax accepted by
+	// Collection.Find) evaluated against the post-image of each change
+	// inside the C core, before the event crosses the CGo boundary. Leave
+	// empty to match every document.
+	Predicate string
+	// Operations restricts which kinds of changes are delivered. Zero
+	// value means WatchAllOperations.
+	Operations WatchOperation
+}
+
+func (o WatchOptions) operationMask() WatchOperation {
+	if o.Operations == 0 {
+		return WatchAllOperations
+	}
+	return o.Operations
+}
+
+// operationBit maps the "insert"/"update"/"delete" strings the core
+// passes to WatchCallback onto the matching WatchOperation bit.
+func operationBit(operation string) WatchOperation {
+	switch operation {
+	case "insert":
+		return WatchInsert
+	case "update":
+		return WatchUpdate
+	case "delete":
+		return WatchDelete
+	default:
+		return 0
+	}
+}
+
+// filterByOperations wraps callback so that only events whose operation is
+// set in mask reach it. The core has no notion of an operation mask itself,
+// so this filtering happens on the Go side of the callback.
+func filterByOperations(mask WatchOperation, callback WatchCallback) WatchCallback {
+	return func(collection, operation, docID, docJSON string) {
+		if operationBit(operation)&mask == 0 {
+			return
+		}
+		callback(collection, operation, docID, docJSON)
+	}
+}
+
+// WatchStartWithOptions is like WatchStart but additionally restricts
+// delivery to opts.Operations and accepts opts.Predicate as the collection's
+// watch filter. The predicate is evaluated by the C core the same as
+// WatchStart's filter argument; the operation mask is applied on the Go side
+// once an event crosses the CGo boundary.
+func (c *Collection) WatchStartWithOptions(opts WatchOptions, callback WatchCallback) (*WatchHandle, error) {
+	return c.WatchStart(opts.Predicate, filterByOperations(opts.operationMask(), callback))
+}
+
+// Watch subscribes to changes across every collection whose name matches
+// collectionRegex (a Go-syntax regular expression, anchored against the
+// full collection name), multiplexing events from all of them onto a
+// single handle instead of requiring one watcher per collection. opts
+// behaves as in Collection.WatchStartWithOptions; the callback's
+// collection argument identifies which collection each event came from.
+//
+// The match against collectionRegex is taken against the collection list at
+// the time Watch is called; collections created afterward are not picked up
+// automatically.
+func (d *Database) Watch(collectionRegex string, opts WatchOptions, callback WatchCallback) (*WatchHandle, error) {
+	if d.db == nil {
+		return nil, &Error{Code: -1, Message: "Database is closed"}
+	}
+
+	re, err := regexp.Compile("^(?:" + collectionRegex + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid collection regex: %w", err)
+	}
+
+	names, err := d.ListCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	var handles []*WatchHandle
+	stopAll := func() {
+		for _, h := range handles {
+			h.Stop()
+		}
+	}
+
+	for _, name := range names {
+		if !re.MatchString(name) {
+			continue
+		}
+
+		coll, err := d.GetCollection(name)
+		if err != nil {
+			stopAll()
+			return nil, err
+		}
+
+		h, err := coll.WatchStartWithOptions(opts, callback)
+		coll.Free()
+		if err != nil {
+			stopAll()
+			return nil, err
+		}
+		handles = append(handles, h)
+	}
+
+	return &WatchHandle{onStop: stopAll}, nil
 }
 
 // Stop stops watching and cleans up resources
@@ -2248,8 +3683,96 @@ func (w *WatchHandle) Stop() {
 		w.handle = nil
 
 		// Cleanup callback registration
-		watchCallbacksMu.Lock()
-		delete(watchCallbacks, w.callbackID)
-		watchCallbacksMu.Unlock()
+		unregisterWatchCallback(w.callbackID)
+	}
+	if w.onStop != nil {
+		w.onStop()
+		w.onStop = nil
+	}
+}
+
+// ChangeEvent is a single change delivered to a WatchStartBatch callback.
+// It carries the same fields WatchCallback receives positionally.
+type ChangeEvent struct {
+	Collection string
+	Operation  string
+	DocID      string
+	DocJSON    string
+}
+
+// BatchOptions controls how WatchStartBatch coalesces events before
+// invoking its callback.
+type BatchOptions struct {
+	// MaxBatch caps how many events accumulate before the callback fires,
+	// regardless of MaxWait. Zero means no cap — only MaxWait flushes.
+	MaxBatch int
+	// MaxWait caps how long a non-empty batch waits for more events
+	// before the callback fires. Zero means wait for MaxBatch only.
+	MaxWait time.Duration
+}
+
+// WatchStartBatch is like WatchStart, but coalesces up to opts.MaxBatch
+// events, or whatever arrives within opts.MaxWait, into a single callback
+// invocation instead of calling callback once per event. Each event still
+// crosses the CGo boundary on its own; only the calls to callback are
+// batched. This
+// matters for consumers forwarding events to a downstream sink (Kafka, an
+// HTTP webhook) where the per-event callback itself is the throughput
+// ceiling.
+func (c *Collection) WatchStartBatch(filter string, opts BatchOptions, callback func([]ChangeEvent)) (*WatchHandle, error) {
+	events := make(chan ChangeEvent, 256)
+
+	handle, err := c.WatchStart(filter, func(coll, op, docID, docJSON string) {
+		events <- ChangeEvent{Collection: coll, Operation: op, DocID: docID, DocJSON: docJSON}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stopCh := make(chan struct{})
+	go batchWatchEvents(events, stopCh, opts, callback)
+	handle.onStop = func() { close(stopCh) }
+
+	return handle, nil
+}
+
+// batchWatchEvents drains events into batches of up to opts.MaxBatch,
+// flushing early once opts.MaxWait elapses since the oldest buffered
+// event, until stopCh is closed (flushing whatever remains first).
+func batchWatchEvents(events <-chan ChangeEvent, stopCh <-chan struct{}, opts BatchOptions, callback func([]ChangeEvent)) {
+	var batch []ChangeEvent
+	var timer *time.Timer
+	var timerC <-chan time.Time
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		callback(batch)
+		batch = nil
+		if timer != nil {
+			timer.Stop()
+			timerC = nil
+		}
+	}
+
+	for {
+		select {
+		case ev := <-events:
+			batch = append(batch, ev)
+			if opts.MaxWait > 0 && timerC == nil {
+				timer = time.NewTimer(opts.MaxWait)
+				timerC = timer.C
+			}
+			if opts.MaxBatch > 0 && len(batch) >= opts.MaxBatch {
+				flush()
+			}
+		case <-timerC:
+			timerC = nil
+			flush()
+		case <-stopCh:
+			flush()
+			return
+		}
 	}
 }
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+func withClaims(ctx context.Context, claims *jwtClaims) context.Context {
+	return context.WithValue(ctx, contextClaimsKey{}, claims)
+}
+
+func claimsFromContext(ctx context.Context) (*jwtClaims, bool) {
+	claims, ok := ctx.Value(contextClaimsKey{}).(*jwtClaims)
+	return claims, ok
+}
+
+func readAll(r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
+	return io.ReadAll(r.Body)
+}
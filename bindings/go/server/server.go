@@ -0,0 +1,219 @@
+// Package server wraps an opened jasonisnthappy database in an embedded
+// HTTP/JSON API, so it can be run as a standalone service (see cmd/jsnh)
+// without writing Go glue around the bindings.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	jasonisnthappy "github.com/sohzm/jasonisnthappy/bindings/go"
+)
+
+// Options configures a Server.
+type Options struct {
+	// Auth, if non-nil, is applied to every request; nil disables auth.
+	Auth *JWTAuth
+	// TLSCertFile/TLSKeyFile enable HTTPS when both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// Server exposes collection CRUD, queries, and change-stream SSE over HTTP
+// for a single opened *jasonisnthappy.Database.
+type Server struct {
+	db   *jasonisnthappy.Database
+	opts Options
+	mux  *http.ServeMux
+}
+
+// New wraps db in a Server ready to be passed to ListenAndServe.
+func New(db *jasonisnthappy.Database, opts Options) *Server {
+	s := &Server{db: db, opts: opts, mux: http.NewServeMux()}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("/collections/", s.withAuth(s.handleCollections))
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe starts the server on addr, serving TLS if Options.TLSCertFile
+// and Options.TLSKeyFile are set.
+func (s *Server) ListenAndServe(addr string) error {
+	if s.opts.TLSCertFile != "" && s.opts.TLSKeyFile != "" {
+		return http.ListenAndServeTLS(addr, s.opts.TLSCertFile, s.opts.TLSKeyFile, s)
+	}
+	return http.ListenAndServe(addr, s)
+}
+
+// /collections/{name}/docs/{id}
+// /collections/{name}/query
+// /collections/{name}/watch
+func (s *Server) handleCollections(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/collections/"), "/"), "/")
+	if len(parts) == 0 || parts[0] == "" {
+		httpError(w, http.StatusNotFound, "collection name required")
+		return
+	}
+	collName := parts[0]
+
+	if !s.authorizedForCollection(r, collName, r.Method) {
+		httpError(w, http.StatusForbidden, "not authorized for collection "+collName)
+		return
+	}
+
+	coll, err := s.db.GetCollection(collName)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer coll.Free()
+
+	switch {
+	case len(parts) == 2 && parts[1] == "query" && r.Method == http.MethodPost:
+		s.handleQuery(w, r, coll)
+	case len(parts) == 2 && parts[1] == "watch" && r.Method == http.MethodGet:
+		s.handleWatchSSE(w, r, coll)
+	case len(parts) == 3 && parts[1] == "docs":
+		s.handleDoc(w, r, coll, parts[2])
+	default:
+		httpError(w, http.StatusNotFound, "unknown route")
+	}
+}
+
+func (s *Server) handleDoc(w http.ResponseWriter, r *http.Request, coll *jasonisnthappy.Collection, id string) {
+	switch r.Method {
+	case http.MethodGet:
+		var doc map[string]interface{}
+		found, err := coll.FindByID(id, &doc)
+		if err != nil {
+			httpError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !found {
+			httpError(w, http.StatusNotFound, "document not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, doc)
+
+	case http.MethodPost:
+		var doc map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		newID, err := coll.Insert(doc)
+		if err != nil {
+			httpError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]string{"id": newID})
+
+	case http.MethodPut:
+		var doc map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			httpError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := coll.UpdateByID(id, doc); err != nil {
+			httpError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case http.MethodDelete:
+		if err := coll.DeleteByID(id); err != nil {
+			httpError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		httpError(w, http.StatusMethodNotAllowed, "unsupported method "+r.Method)
+	}
+}
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request, coll *jasonisnthappy.Collection) {
+	body, err := readAll(r)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var results []map[string]interface{}
+	if err := coll.Find(string(body), &results); err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// handleWatchSSE streams change events for coll as Server-Sent Events. The
+// underlying watch has no concept of a resume token, so reconnecting
+// clients simply start tailing from whatever changes occur after they
+// (re)connect; nothing missed while disconnected is replayed.
+func (s *Server) handleWatchSSE(w http.ResponseWriter, r *http.Request, coll *jasonisnthappy.Collection) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		httpError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events := make(chan string, 64)
+	cb := func(collection, operation, docID, docJSON string) {
+		payload, _ := json.Marshal(map[string]string{
+			"collection": collection,
+			"operation":  operation,
+			"id":         docID,
+			"doc":        docJSON,
+		})
+		select {
+		case events <- fmt.Sprintf("data: %s\n\n", payload):
+		default:
+			// Drop the event rather than block the watcher goroutine.
+		}
+	}
+
+	handle, err := coll.WatchStart("", cb)
+	if err != nil {
+		httpError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer handle.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-events:
+			if _, err := w.Write([]byte(ev)); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func httpError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -1,6 +1,7 @@
 package jasonisnthappy
 
 import (
+	"errors"
 	"os"
 	"testing"
 )
@@ -152,3 +153,48 @@ func TestBasicCRUD(t *testing.T) {
 	}
 	t.Log("✅ Verified deletion")
 }
+
+func TestDuplicateKeyError(t *testing.T) {
+	// Create temp database
+	dbPath := "/tmp/test_duplicate_key.db"
+	defer os.Remove(dbPath)
+	defer os.Remove(dbPath + ".wal")
+	defer os.Remove(dbPath + ".lock")
+
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.EnsureIndex("users", "email_idx", IndexSpec{Fields: []string{"email"}, Unique: true}); err != nil {
+		t.Fatalf("Failed to create unique index: %v", err)
+	}
+
+	coll, err := db.GetCollection("users")
+	if err != nil {
+		t.Fatalf("Failed to get collection: %v", err)
+	}
+	defer coll.Free()
+
+	if _, err := coll.Insert(map[string]interface{}{"_id": "user1", "email": "alice@example.com"}); err != nil {
+		t.Fatalf("Failed to insert first document: %v", err)
+	}
+
+	_, err = coll.Insert(map[string]interface{}{"_id": "user2", "email": "alice@example.com"})
+	if err == nil {
+		t.Fatal("Expected a unique constraint violation on the second insert")
+	}
+	if !IsDup(err) {
+		t.Fatalf("Expected IsDup to report true, got error: %v", err)
+	}
+
+	var dup *ErrDuplicateKey
+	if !errors.As(err, &dup) {
+		t.Fatalf("Expected *ErrDuplicateKey, got %T", err)
+	}
+	if dup.Index != "email_idx" {
+		t.Fatalf("Expected Index %q, got %q", "email_idx", dup.Index)
+	}
+	t.Logf("✅ Got expected duplicate key error: %v", dup)
+}
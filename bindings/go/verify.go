@@ -0,0 +1,127 @@
+package jasonisnthappy
+
+import (
+	"bufio"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+//go:embed libraries.sum
+var librariesSumData string
+
+// Verify recomputes the SHA-256 of the native library this build links
+// against and compares it to the checksum recorded in libraries.sum,
+// returning an error if the file is missing, unreadable, or doesn't
+// match. install.go, download_static.go, and cmd/install/main.go already
+// run the same check before renaming a freshly downloaded artifact into
+// place; Verify exists so a long-running process can also catch a
+// library that was swapped out on disk after install.
+func Verify() error {
+	platformDir, destName, err := installedLibraryPaths()
+	if err != nil {
+		return err
+	}
+
+	sumKey, err := librarySumKey()
+	if err != nil {
+		return err
+	}
+
+	sums, err := parseLibrarySums(librariesSumData)
+	if err != nil {
+		return err
+	}
+
+	want, ok := sums[sumKey]
+	if !ok {
+		return fmt.Errorf("jasonisnthappy: no checksum recorded for %s in libraries.sum", sumKey)
+	}
+
+	path := filepath.Join("lib", platformDir, destName)
+	got, err := sha256SumFile(path)
+	if err != nil {
+		return fmt.Errorf("jasonisnthappy: reading %s: %w", path, err)
+	}
+	if got != want {
+		return fmt.Errorf("jasonisnthappy: checksum mismatch for %s: got %s, want %s", path, got, want)
+	}
+	return nil
+}
+
+// librarySumKey returns the libraries.sum key for the dynamic library
+// this build links against, e.g. "linux-amd64-dynamic.so".
+func librarySumKey() (string, error) {
+	var ext string
+	switch runtime.GOOS {
+	case "darwin":
+		ext = "dylib"
+	case "linux":
+		ext = "so"
+	case "windows":
+		ext = "dll"
+	default:
+		return "", fmt.Errorf("jasonisnthappy: unsupported platform %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	return fmt.Sprintf("%s-%s-dynamic.%s", runtime.GOOS, runtime.GOARCH, ext), nil
+}
+
+// installedLibraryPaths returns the lib/<platformDir>/<destName> install.go
+// downloads the dynamic library to for the current platform.
+func installedLibraryPaths() (platformDir, destName string, err error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return "darwin-" + runtime.GOARCH, "libjasonisnthappy.dylib", nil
+	case "linux":
+		return "linux-" + runtime.GOARCH, "libjasonisnthappy.so", nil
+	case "windows":
+		return "windows-" + runtime.GOARCH, "jasonisnthappy.dll", nil
+	default:
+		return "", "", fmt.Errorf("jasonisnthappy: unsupported platform %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+}
+
+// parseLibrarySums parses a libraries.sum file's contents into a map of
+// artifact name to its "h1:<base64-sha256>" checksum. Blank lines and
+// lines starting with "#" are ignored.
+func parseLibrarySums(data string) (map[string]string, error) {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("jasonisnthappy: malformed libraries.sum line: %q", line)
+		}
+		sums[fields[0]] = fields[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// sha256SumFile streams path through SHA-256 and returns it in the same
+// "h1:<base64>" form libraries.sum uses.
+func sha256SumFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
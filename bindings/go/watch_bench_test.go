@@ -0,0 +1,109 @@
+package jasonisnthappy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+// BenchmarkWatchFilterInCore measures throughput when the "type": "login"
+// predicate is evaluated inside the C core via WatchStartWithOptions, so
+// non-matching events never cross the CGo boundary.
+func BenchmarkWatchFilterInCore(b *testing.B) {
+	dbPath := "/tmp/bench_watch_filter_core.db"
+	defer os.Remove(dbPath)
+	defer os.Remove(dbPath + ".wal")
+	defer os.Remove(dbPath + ".lock")
+
+	db, err := Open(dbPath)
+	if err != nil {
+		b.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	coll, err := db.GetCollection("events")
+	if err != nil {
+		b.Fatalf("Failed to get collection: %v", err)
+	}
+	defer coll.Free()
+
+	var matched int64
+	handle, err := coll.WatchStartWithOptions(WatchOptions{
+		Predicate:  `{"type": "login"}`,
+		Operations: WatchInsert,
+	}, func(collection, operation, docID, docJSON string) {
+		atomic.AddInt64(&matched, 1)
+	})
+	if err != nil {
+		b.Fatalf("Failed to start watch: %v", err)
+	}
+	defer handle.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		typ := "logout"
+		if i%10 == 0 {
+			typ = "login"
+		}
+		doc, _ := json.Marshal(map[string]interface{}{"_id": fmt.Sprintf("e%d", i), "type": typ})
+		if _, err := coll.Insert(json.RawMessage(doc)); err != nil {
+			b.Fatalf("Failed to insert: %v", err)
+		}
+	}
+	b.StopTimer()
+	handle.Stop()
+	b.ReportMetric(float64(atomic.LoadInt64(&matched))/float64(b.N), "matched/op")
+}
+
+// BenchmarkWatchFilterInGo measures the same workload filtering "type":
+// "login" events out in the Go callback instead, paying the CGo transition
+// cost for every event regardless of whether the caller wants it.
+func BenchmarkWatchFilterInGo(b *testing.B) {
+	dbPath := "/tmp/bench_watch_filter_go.db"
+	defer os.Remove(dbPath)
+	defer os.Remove(dbPath + ".wal")
+	defer os.Remove(dbPath + ".lock")
+
+	db, err := Open(dbPath)
+	if err != nil {
+		b.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	coll, err := db.GetCollection("events")
+	if err != nil {
+		b.Fatalf("Failed to get collection: %v", err)
+	}
+	defer coll.Free()
+
+	var matched int64
+	handle, err := coll.WatchStart("", func(collection, operation, docID, docJSON string) {
+		var doc map[string]interface{}
+		if json.Unmarshal([]byte(docJSON), &doc) == nil && doc["type"] == "login" {
+			atomic.AddInt64(&matched, 1)
+		}
+	})
+	if err != nil {
+		b.Fatalf("Failed to start watch: %v", err)
+	}
+	defer handle.Stop()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		typ := "logout"
+		if i%10 == 0 {
+			typ = "login"
+		}
+		doc, _ := json.Marshal(map[string]interface{}{"_id": fmt.Sprintf("e%d", i), "type": typ})
+		if _, err := coll.Insert(json.RawMessage(doc)); err != nil {
+			b.Fatalf("Failed to insert: %v", err)
+		}
+	}
+	b.StopTimer()
+	handle.Stop()
+	b.ReportMetric(float64(atomic.LoadInt64(&matched))/float64(b.N), "matched/op")
+}
@@ -0,0 +1,313 @@
+package jasonisnthappy
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// WebUIOptions configures a Database.StartWebUIWithOptions call.
+//
+// There's no jasonisnthappy_start_web_server_with_options — the FFI's
+// web server takes just an address, with no TLS, auth, or scoping knobs
+// of its own. StartWebUIWithOptions gets there by binding the real web
+// server to a loopback address nobody outside this process can reach,
+// then fronting it with an ordinary Go net/http server that terminates
+// TLS, checks auth, and enforces ReadOnly/AllowedCollections/CORSOrigins
+// before reverse-proxying the request through. See StartWebUIWithOptions.
+type WebUIOptions struct {
+	// Addr is the address the public-facing listener binds, e.g.
+	// "0.0.0.0:8443".
+	Addr string
+
+	// TLSCertFile and TLSKeyFile, if both set, serve Addr over TLS.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// BasicAuth maps username to password. If set (and TokenAuth isn't),
+	// every request must present matching HTTP Basic credentials.
+	BasicAuth map[string]string
+	// TokenAuth, if set, verifies a bearer token from the Authorization
+	// header and returns the identified user. Takes precedence over
+	// BasicAuth if both are set.
+	TokenAuth func(token string) (user string, ok bool)
+
+	// ReadOnly rejects any request whose method isn't GET, HEAD, or
+	// OPTIONS with 403 before it reaches the web server.
+	ReadOnly bool
+	// AllowedCollections, if non-empty, rejects requests whose path
+	// doesn't reference one of the named collections. Matching is a
+	// best-effort path-segment scan: the web server's own routes aren't
+	// documented at the FFI level, so this can't parse the request the
+	// way the server itself does.
+	AllowedCollections []string
+	// CORSOrigins, if non-empty, echoes back a matching Origin in
+	// Access-Control-Allow-Origin and answers OPTIONS preflights;
+	// requests from other origins are otherwise left alone (CORS is
+	// enforced by the browser, not this proxy).
+	CORSOrigins []string
+}
+
+// webUIMetrics holds the running counters Database.StartWebUIWithOptions
+// exposes at GET /metrics, in Prometheus text exposition format.
+//
+// These are proxy-level HTTP counters (method, status, latency), not
+// counters tagged by query/bulk/watch semantics — the web server is an
+// opaque binary behind jasonisnthappy_start_web_server with no
+// instrumentation hooks exposed through the FFI, so this is the honest
+// ceiling on what can be observed from outside it.
+type webUIMetrics struct {
+	requestsTotal    int64
+	requestErrors    int64
+	requestSeconds   int64 // nanoseconds, summed
+	rejectedReadOnly int64
+	rejectedScope    int64
+	rejectedAuth     int64
+}
+
+// WebUIHandle is a running Database.StartWebUIWithOptions server.
+type WebUIHandle struct {
+	inner   *WebServer
+	http    *http.Server
+	metrics *webUIMetrics
+}
+
+// Stop shuts down the public-facing listener and the underlying web
+// server it was proxying to.
+func (h *WebUIHandle) Stop() {
+	if h.http != nil {
+		h.http.Close()
+	}
+	if h.inner != nil {
+		h.inner.Stop()
+	}
+}
+
+// StartWebUIWithOptions starts the web UI behind a Go-side reverse proxy
+// that applies opts.TLSCertFile/TLSKeyFile, BasicAuth/TokenAuth,
+// ReadOnly, AllowedCollections, and CORSOrigins, and serves Prometheus
+// metrics at GET /metrics — none of which jasonisnthappy_start_web_server
+// itself supports. The real server binds a loopback-only address picked
+// by the OS, so it's unreachable except through this proxy.
+func (d *Database) StartWebUIWithOptions(opts WebUIOptions) (*WebUIHandle, error) {
+	internalAddr, err := pickLoopbackAddr()
+	if err != nil {
+		return nil, fmt.Errorf("jasonisnthappy: picking an internal address for the web server: %w", err)
+	}
+
+	inner, err := d.StartWebUI(internalAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := url.Parse("http://" + internalAddr)
+	if err != nil {
+		inner.Stop()
+		return nil, err
+	}
+
+	metrics := &webUIMetrics{}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	handler := metricsHandler(metrics, authMiddleware(&opts, metrics,
+		scopeMiddleware(&opts, metrics,
+			readOnlyMiddleware(&opts, metrics,
+				corsMiddleware(&opts, proxy)))))
+
+	srv := &http.Server{Addr: opts.Addr, Handler: handler}
+	handle := &WebUIHandle{inner: inner, http: srv, metrics: metrics}
+
+	ln, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		inner.Stop()
+		return nil, err
+	}
+
+	go func() {
+		var serveErr error
+		if opts.TLSCertFile != "" && opts.TLSKeyFile != "" {
+			serveErr = srv.ServeTLS(ln, opts.TLSCertFile, opts.TLSKeyFile)
+		} else {
+			serveErr = srv.Serve(ln)
+		}
+		_ = serveErr // Stop() calling http.Server.Close() is the expected way this returns.
+	}()
+
+	return handle, nil
+}
+
+// pickLoopbackAddr asks the OS for a free loopback port, the same trick
+// net/http/httptest uses, so the internal web server binds somewhere
+// only this proxy can reach. There's a narrow window between closing
+// this probe listener and the web server binding the same port where
+// another process could grab it; that's an accepted tradeoff since
+// nothing in the FFI reports back which port jasonisnthappy_start_web_server
+// actually bound.
+func pickLoopbackAddr() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return "127.0.0.1:" + strconv.Itoa(port), nil
+}
+
+func authMiddleware(opts *WebUIOptions, m *webUIMetrics, next http.Handler) http.Handler {
+	if opts.TokenAuth == nil && len(opts.BasicAuth) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if opts.TokenAuth != nil {
+			authHeader := r.Header.Get("Authorization")
+			if token, ok := strings.CutPrefix(authHeader, "Bearer "); ok {
+				if _, ok := opts.TokenAuth(token); ok {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			atomic.AddInt64(&m.rejectedAuth, 1)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		user, pass, ok := r.BasicAuth()
+		if ok {
+			if want, exists := opts.BasicAuth[user]; exists &&
+				subtle.ConstantTimeCompare([]byte(want), []byte(pass)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		atomic.AddInt64(&m.rejectedAuth, 1)
+		w.Header().Set("WWW-Authenticate", `Basic realm="jasonisnthappy"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+func readOnlyMiddleware(opts *WebUIOptions, m *webUIMetrics, next http.Handler) http.Handler {
+	if !opts.ReadOnly {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+		default:
+			atomic.AddInt64(&m.rejectedReadOnly, 1)
+			http.Error(w, "read-only web UI", http.StatusForbidden)
+		}
+	})
+}
+
+func scopeMiddleware(opts *WebUIOptions, m *webUIMetrics, next http.Handler) http.Handler {
+	if len(opts.AllowedCollections) == 0 {
+		return next
+	}
+
+	allowed := make(map[string]bool, len(opts.AllowedCollections))
+	for _, name := range opts.AllowedCollections {
+		allowed[name] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, segment := range strings.Split(r.URL.Path, "/") {
+			if allowed[segment] {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		atomic.AddInt64(&m.rejectedScope, 1)
+		http.Error(w, "collection not allowed", http.StatusForbidden)
+	})
+}
+
+func corsMiddleware(opts *WebUIOptions, next http.Handler) http.Handler {
+	if len(opts.CORSOrigins) == 0 {
+		return next
+	}
+
+	allowed := make(map[string]bool, len(opts.CORSOrigins))
+	for _, origin := range opts.CORSOrigins {
+		allowed[origin] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if allowed[origin] {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func metricsHandler(m *webUIMetrics, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metrics" && r.Method == http.MethodGet {
+			writeMetrics(w, m)
+			return
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		atomic.AddInt64(&m.requestsTotal, 1)
+		atomic.AddInt64(&m.requestSeconds, int64(time.Since(start)))
+		if rec.status >= 400 {
+			atomic.AddInt64(&m.requestErrors, 1)
+		}
+	})
+}
+
+// statusRecorder captures the status code a wrapped handler wrote, so
+// metricsHandler can count errors without the inner handler's
+// cooperation.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func writeMetrics(w http.ResponseWriter, m *webUIMetrics) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	requests := atomic.LoadInt64(&m.requestsTotal)
+	seconds := float64(atomic.LoadInt64(&m.requestSeconds)) / float64(time.Second)
+
+	fmt.Fprintf(w, "# HELP jasonisnthappy_webui_requests_total Total requests proxied to the web UI.\n")
+	fmt.Fprintf(w, "# TYPE jasonisnthappy_webui_requests_total counter\n")
+	fmt.Fprintf(w, "jasonisnthappy_webui_requests_total %d\n", requests)
+
+	fmt.Fprintf(w, "# HELP jasonisnthappy_webui_request_errors_total Proxied requests that returned a 4xx/5xx status.\n")
+	fmt.Fprintf(w, "# TYPE jasonisnthappy_webui_request_errors_total counter\n")
+	fmt.Fprintf(w, "jasonisnthappy_webui_request_errors_total %d\n", atomic.LoadInt64(&m.requestErrors))
+
+	fmt.Fprintf(w, "# HELP jasonisnthappy_webui_request_seconds_total Cumulative time spent proxying requests.\n")
+	fmt.Fprintf(w, "# TYPE jasonisnthappy_webui_request_seconds_total counter\n")
+	fmt.Fprintf(w, "jasonisnthappy_webui_request_seconds_total %f\n", seconds)
+
+	fmt.Fprintf(w, "# HELP jasonisnthappy_webui_rejected_total Requests rejected by the proxy before reaching the web server, by reason.\n")
+	fmt.Fprintf(w, "# TYPE jasonisnthappy_webui_rejected_total counter\n")
+	fmt.Fprintf(w, "jasonisnthappy_webui_rejected_total{reason=\"auth\"} %d\n", atomic.LoadInt64(&m.rejectedAuth))
+	fmt.Fprintf(w, "jasonisnthappy_webui_rejected_total{reason=\"read_only\"} %d\n", atomic.LoadInt64(&m.rejectedReadOnly))
+	fmt.Fprintf(w, "jasonisnthappy_webui_rejected_total{reason=\"scope\"} %d\n", atomic.LoadInt64(&m.rejectedScope))
+}